@@ -0,0 +1,214 @@
+package layergl
+
+import (
+	"fmt"
+	"time"
+)
+
+type AnimationMode int
+
+const (
+	// Restart from the first frame after the last one.
+	AnimationLoop AnimationMode = iota
+
+	// Play forward and backward alternately.
+	AnimationPingPong
+
+	// Stop at the last frame.
+	AnimationOnce
+)
+
+type AnimationFrame struct {
+	Region   TextureRegion
+	Duration time.Duration
+}
+
+// Animation is a sequence of texture regions with their display times.
+type Animation struct {
+	Frames []AnimationFrame
+	Mode   AnimationMode
+}
+
+// Creates Animation from the frames of the sprite sheet in the range from-to,
+// inclusive. Frames without own duration, such as from a grid sheet, are
+// shown for frameDuration.
+func NewAnimation(sheet *SpriteSheet, from, to int, frameDuration time.Duration, mode AnimationMode) *Animation {
+	a := &Animation{Mode: mode}
+	for i := from; i <= to && i < len(sheet.Frames); i++ {
+		f := sheet.Frames[i]
+		if f.Duration == 0 {
+			f.Duration = frameDuration
+		}
+
+		a.Frames = append(a.Frames, AnimationFrame{f.Region, f.Duration})
+	}
+
+	return a
+}
+
+// Creates Animation from the Aseprite tag of the sprite sheet. Tag direction
+// "pingpong" plays in AnimationPingPong mode, "reverse" plays frames backwards,
+// other tags loop forward.
+func (s *SpriteSheet) Animation(tag string) (*Animation, error) {
+	t, ok := s.Tag(tag)
+	if !ok {
+		return nil, fmt.Errorf("sprite sheet: no tag %q", tag)
+	}
+
+	a := NewAnimation(s, t.From, t.To, 0, AnimationLoop)
+	switch t.Direction {
+	case "pingpong":
+		a.Mode = AnimationPingPong
+	case "reverse":
+		for i, j := 0, len(a.Frames)-1; i < j; i, j = i+1, j-1 {
+			a.Frames[i], a.Frames[j] = a.Frames[j], a.Frames[i]
+		}
+	}
+
+	return a, nil
+}
+
+// Returns animations for all tags of the sprite sheet by name.
+func (s *SpriteSheet) Animations() map[string]*Animation {
+	animations := make(map[string]*Animation, len(s.Tags))
+	for _, t := range s.Tags {
+		animations[t.Name], _ = s.Animation(t.Name)
+	}
+
+	return animations
+}
+
+func (a *Animation) duration() (total time.Duration) {
+	for _, f := range a.Frames {
+		total += f.Duration
+	}
+
+	return total
+}
+
+// AnimatedSprite is a Texture whose region is advanced through an Animation.
+// It is drawn with DrawTexture like any other texture.
+type AnimatedSprite struct {
+	*Texture
+	Animation *Animation
+
+	// Called when a new frame is shown.
+	OnFrame func(frame int)
+
+	// Called when an AnimationOnce animation ends, or when a looping
+	// animation completes a cycle.
+	OnFinish func()
+
+	frame     int
+	elapsed   time.Duration
+	backwards bool
+	finished  bool
+}
+
+// Creates AnimatedSprite of the given size drawing frames of the texture.
+func NewAnimatedSprite(texture *Texture, animation *Animation, width, height float64) *AnimatedSprite {
+	s := &AnimatedSprite{
+		Texture: texture.SubTexture(TextureRegion{}, width, height),
+	}
+	s.Play(animation)
+
+	return s
+}
+
+// Starts the animation from its first frame.
+func (s *AnimatedSprite) Play(animation *Animation) {
+	s.Animation = animation
+	s.frame = 0
+	s.elapsed = 0
+	s.backwards = false
+	s.finished = false
+	s.showFrame()
+}
+
+// Returns index of the current frame.
+func (s *AnimatedSprite) Frame() int {
+	return s.frame
+}
+
+// Returns true when an AnimationOnce animation has reached its end.
+func (s *AnimatedSprite) Finished() bool {
+	return s.finished
+}
+
+// Advances the animation by dt.
+func (s *AnimatedSprite) Update(dt time.Duration) {
+	a := s.Animation
+	if a == nil || s.finished || len(a.Frames) == 0 || a.duration() <= 0 {
+		return
+	}
+
+	s.elapsed += dt
+	for s.elapsed >= a.Frames[s.frame].Duration {
+		s.elapsed -= a.Frames[s.frame].Duration
+
+		if !s.advance() {
+			s.elapsed = 0
+			return
+		}
+	}
+}
+
+// Moves to the next frame, returns false if the animation has finished.
+func (s *AnimatedSprite) advance() bool {
+	a := s.Animation
+	last := len(a.Frames) - 1
+
+	next := s.frame + 1
+	if s.backwards {
+		next = s.frame - 1
+	}
+
+	cycle := false
+	switch a.Mode {
+	case AnimationLoop:
+		if next > last {
+			next = 0
+			cycle = true
+		}
+	case AnimationPingPong:
+		if next > last {
+			s.backwards = true
+			next = last - 1
+		} else if next < 0 {
+			s.backwards = false
+			next = 1
+			cycle = true
+		}
+		if next < 0 || next > last {
+			next = 0
+		}
+	case AnimationOnce:
+		if next > last {
+			s.finished = true
+			if s.OnFinish != nil {
+				s.OnFinish()
+			}
+			return false
+		}
+	}
+
+	s.frame = next
+	s.showFrame()
+
+	if cycle && s.OnFinish != nil {
+		s.OnFinish()
+	}
+
+	return true
+}
+
+func (s *AnimatedSprite) showFrame() {
+	if s.Animation == nil || len(s.Animation.Frames) == 0 {
+		return
+	}
+
+	s.Region = s.Animation.Frames[s.frame].Region
+	if s.OnFrame != nil {
+		s.OnFrame(s.frame)
+	}
+}
@@ -0,0 +1,132 @@
+package layergl
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+// Sheet of four 10x10 frames in a row.
+func testSpriteSheet() *SpriteSheet {
+	return NewSpriteSheetGrid(&Texture{size: image.Point{40, 10}}, 10, 10)
+}
+
+func TestAnimatedSprite(t *testing.T) {
+	const ms = time.Millisecond
+
+	tests := []struct {
+		name     string
+		mode     AnimationMode
+		frames   int
+		dt       time.Duration
+		want     []int
+		finishes []int
+		finished bool
+	}{
+		{"loop", AnimationLoop, 4, 100 * ms, []int{1, 2, 3, 0, 1}, []int{0, 0, 0, 1, 1}, false},
+		{"loop in long updates", AnimationLoop, 4, 250 * ms, []int{2, 1, 3}, []int{0, 1, 1}, false},
+		{"short updates", AnimationLoop, 4, 40 * ms, []int{0, 0, 1, 1, 2}, []int{0, 0, 0, 0, 0}, false},
+		{"ping-pong", AnimationPingPong, 4, 100 * ms, []int{1, 2, 3, 2, 1, 0, 1}, []int{0, 0, 0, 0, 0, 0, 1}, false},
+		{"ping-pong of two frames", AnimationPingPong, 2, 100 * ms, []int{1, 0, 1, 0}, []int{0, 0, 1, 1}, false},
+		{"ping-pong of one frame", AnimationPingPong, 1, 100 * ms, []int{0, 0}, []int{0, 1}, false},
+		{"once", AnimationOnce, 4, 100 * ms, []int{1, 2, 3, 3, 3}, []int{0, 0, 0, 1, 1}, true},
+		{"once at once", AnimationOnce, 4, time.Second, []int{3, 3}, []int{1, 1}, true},
+	}
+
+	for _, test := range tests {
+		sheet := testSpriteSheet()
+		a := NewAnimation(sheet, 0, test.frames-1, 100*ms, test.mode)
+		s := NewAnimatedSprite(sheet.Texture, a, 10, 10)
+
+		finishes, shown := 0, 0
+		s.OnFinish = func() { finishes++ }
+		s.OnFrame = func(int) { shown++ }
+
+		changes, last := 0, s.Frame()
+		for i, want := range test.want {
+			s.Update(test.dt)
+			if s.Frame() != want || finishes != test.finishes[i] {
+				t.Errorf("%s: update %d: frame %d, finished %d times, want %d, %d", test.name, i, s.Frame(), finishes, want, test.finishes[i])
+			}
+			if s.Region != a.Frames[s.Frame()].Region {
+				t.Errorf("%s: update %d: region %v of frame %d", test.name, i, s.Region, s.Frame())
+			}
+			if s.Frame() != last {
+				changes++
+				last = s.Frame()
+			}
+		}
+		if s.Finished() != test.finished {
+			t.Errorf("%s: finished %v, want %v", test.name, s.Finished(), test.finished)
+		}
+
+		// OnFrame reports every frame shown, including the skipped ones.
+		if shown < changes {
+			t.Errorf("%s: OnFrame called %d times for %d frame changes", test.name, shown, changes)
+		}
+	}
+}
+
+func TestAnimatedSpritePlay(t *testing.T) {
+	sheet := testSpriteSheet()
+	once := NewAnimation(sheet, 0, 1, 100*time.Millisecond, AnimationOnce)
+	s := NewAnimatedSprite(sheet.Texture, once, 10, 10)
+
+	s.Update(time.Second)
+	if !s.Finished() || s.Frame() != 1 {
+		t.Fatalf("frame %d, finished %v", s.Frame(), s.Finished())
+	}
+
+	s.Play(NewAnimation(sheet, 2, 3, 100*time.Millisecond, AnimationLoop))
+	if s.Finished() || s.Frame() != 0 || s.Region != sheet.Frames[2].Region {
+		t.Fatalf("after Play: frame %d, region %v, finished %v", s.Frame(), s.Region, s.Finished())
+	}
+
+	// Animations without duration do not advance.
+	s.Play(NewAnimation(sheet, 0, 3, 0, AnimationLoop))
+	s.Update(time.Second)
+	if s.Frame() != 0 {
+		t.Errorf("frame %d of animation without duration", s.Frame())
+	}
+}
+
+func TestSpriteSheetAnimation(t *testing.T) {
+	sheet := testSpriteSheet()
+	sheet.Frames[1].Duration = 50 * time.Millisecond
+	sheet.Tags = []SpriteTag{
+		{"forward", 0, 2, "forward"},
+		{"reverse", 0, 2, "reverse"},
+		{"pingpong", 1, 3, "pingpong"},
+	}
+
+	tests := []struct {
+		tag    string
+		mode   AnimationMode
+		frames []int
+	}{
+		{"forward", AnimationLoop, []int{0, 1, 2}},
+		{"reverse", AnimationLoop, []int{2, 1, 0}},
+		{"pingpong", AnimationPingPong, []int{1, 2, 3}},
+	}
+	for _, test := range tests {
+		a, err := sheet.Animation(test.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Mode != test.mode || len(a.Frames) != len(test.frames) {
+			t.Fatalf("%s: mode %v with %d frames", test.tag, a.Mode, len(a.Frames))
+		}
+		for i, f := range test.frames {
+			if a.Frames[i].Region != sheet.Frames[f].Region || a.Frames[i].Duration != sheet.Frames[f].Duration {
+				t.Errorf("%s: frame %d is %v, want sheet frame %d", test.tag, i, a.Frames[i], f)
+			}
+		}
+	}
+
+	if _, err := sheet.Animation("missing"); err == nil {
+		t.Error("animation of a missing tag")
+	}
+	if n := len(sheet.Animations()); n != 3 {
+		t.Errorf("%d animations, want 3", n)
+	}
+}
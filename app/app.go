@@ -0,0 +1,78 @@
+// Package app runs the main loop of a layergl program, dispatching window
+// system input as events. The window itself is opened by package glfwapp.
+package app
+
+import (
+	"github.com/iostapyshyn/layergl"
+	"time"
+)
+
+// Window is the part of the window system used by App. It is implemented
+// with GLFW by glfwapp.Open and by FakeWindow for running without a display.
+type Window interface {
+	// Returns size of the framebuffer in pixels, which is the size of the
+	// layergl screen.
+	Size() (width, height int)
+	ShouldClose() bool
+	SetShouldClose(bool)
+	SetTitle(string)
+
+	// Returns events received since the previous call.
+	PollEvents() []Event
+
+	SwapBuffers()
+	Destroy()
+}
+
+// App runs the main loop: every frame it dispatches the events, then calls
+// Update with the time since the previous frame and Draw.
+type App struct {
+	Window Window
+
+	OnEvent func(Event)
+	Update  func(dt time.Duration)
+	Draw    func()
+
+	// Returns the current time, time.Now by default.
+	Now func() time.Time
+}
+
+// Creates App running in the window.
+func New(w Window) *App {
+	return &App{Window: w, Now: time.Now}
+}
+
+// Runs the loop until the window should close.
+func (a *App) Run() {
+	last := a.Now()
+	for !a.Window.ShouldClose() {
+		for _, e := range a.Window.PollEvents() {
+			if a.OnEvent != nil {
+				a.OnEvent(e)
+			}
+		}
+
+		now := a.Now()
+		if a.Update != nil {
+			a.Update(now.Sub(last))
+		}
+		last = now
+
+		if a.Draw != nil {
+			a.Draw()
+		}
+		a.Window.SwapBuffers()
+	}
+}
+
+// Destroys the window.
+func (a *App) Close() {
+	a.Window.Destroy()
+}
+
+// Converts event position to layergl coordinates, with the origin at the
+// bottom left corner.
+func (a *App) Point(x, y float64) layergl.Point {
+	_, height := a.Window.Size()
+	return layergl.Point{X: x, Y: float64(height) - y}
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"fmt"
+	"github.com/iostapyshyn/layergl"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Returns clock advancing by step on every call.
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Unix(0, 0)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+// Returns App in the fake window recording the calls of its handlers.
+func newRecordedApp(w *FakeWindow) (*App, *[]string) {
+	calls := new([]string)
+	a := New(w)
+	a.Now = fakeClock(10 * time.Millisecond)
+	a.OnEvent = func(e Event) {
+		*calls = append(*calls, fmt.Sprintf("event %T", e))
+	}
+	a.Update = func(dt time.Duration) {
+		*calls = append(*calls, fmt.Sprintf("update %v", dt))
+	}
+	a.Draw = func() {
+		*calls = append(*calls, "draw")
+	}
+	return a, calls
+}
+
+func TestRunDispatchesEvents(t *testing.T) {
+	w := NewFakeWindow(640, 480)
+	w.MaxFrames = 4
+	w.Push(KeyEvent{Key: KeyA, Action: Press}, MouseMoveEvent{10, 20})
+	w.Push()
+	w.Push(ResizeEvent{800, 600})
+
+	a, calls := newRecordedApp(w)
+	a.Run()
+
+	want := []string{
+		"event app.KeyEvent", "event app.MouseMoveEvent", "update 10ms", "draw",
+		"update 10ms", "draw",
+		"event app.ResizeEvent", "update 10ms", "draw",
+		"update 10ms", "draw",
+	}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+	if w.Frames != 4 {
+		t.Errorf("%d frames, want 4", w.Frames)
+	}
+
+	// Points are converted with the height after the resize.
+	if p, want := a.Point(10, 20), (layergl.Point{X: 10, Y: 580}); p != want {
+		t.Errorf("Point = %v, want %v", p, want)
+	}
+}
+
+func TestRunStopsAfterCloseEvent(t *testing.T) {
+	w := NewFakeWindow(640, 480)
+	w.MaxFrames = 10
+	w.Push(CloseEvent{}, KeyEvent{Key: KeyEscape, Action: Release})
+	w.Push(KeyEvent{Key: KeyA, Action: Press})
+
+	a, calls := newRecordedApp(w)
+	a.Run()
+
+	// The frame receiving the event is completed, with all of its events.
+	want := []string{"event app.CloseEvent", "event app.KeyEvent", "update 10ms", "draw"}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+	if w.Frames != 1 {
+		t.Errorf("%d frames, want 1", w.Frames)
+	}
+}
+
+func TestCloseEventCancelled(t *testing.T) {
+	w := NewFakeWindow(640, 480)
+	w.MaxFrames = 3
+	w.Push(CloseEvent{})
+
+	a := New(w)
+	var closes int
+	a.OnEvent = func(e Event) {
+		if _, ok := e.(CloseEvent); ok {
+			closes++
+			a.Window.SetShouldClose(false)
+		}
+	}
+	a.Run()
+
+	if closes != 1 || w.Frames != 3 {
+		t.Errorf("%d close events, %d frames, want 1 and 3", closes, w.Frames)
+	}
+}
+
+func TestSetShouldCloseFromDraw(t *testing.T) {
+	w := NewFakeWindow(640, 480)
+	a := New(w)
+	a.Draw = func() {
+		if w.Frames == 1 {
+			a.Window.SetShouldClose(true)
+		}
+	}
+	a.Run()
+
+	if w.Frames != 2 {
+		t.Errorf("%d frames, want 2", w.Frames)
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+// Event is one of KeyEvent, CharEvent, MouseButtonEvent, MouseMoveEvent,
+// ScrollEvent, ResizeEvent and CloseEvent. Positions are in framebuffer
+// pixels from the top left corner, see App.Point.
+type Event interface{}
+
+type Action int
+
+const (
+	Release Action = iota
+	Press
+	Repeat
+)
+
+type Modifier int
+
+const (
+	ModShift Modifier = 1 << iota
+	ModControl
+	ModAlt
+	ModSuper
+)
+
+type MouseButton int
+
+const (
+	MouseLeft MouseButton = iota
+	MouseRight
+	MouseMiddle
+)
+
+// Key is a physical key, the values are the same as in GLFW.
+type Key int
+
+const (
+	KeyUnknown Key = -1
+	KeySpace   Key = 32
+	Key0       Key = 48
+	Key1       Key = 49
+	Key2       Key = 50
+	Key3       Key = 51
+	Key4       Key = 52
+	Key5       Key = 53
+	Key6       Key = 54
+	Key7       Key = 55
+	Key8       Key = 56
+	Key9       Key = 57
+	KeyA       Key = 65
+	KeyB       Key = 66
+	KeyC       Key = 67
+	KeyD       Key = 68
+	KeyE       Key = 69
+	KeyF       Key = 70
+	KeyG       Key = 71
+	KeyH       Key = 72
+	KeyI       Key = 73
+	KeyJ       Key = 74
+	KeyK       Key = 75
+	KeyL       Key = 76
+	KeyM       Key = 77
+	KeyN       Key = 78
+	KeyO       Key = 79
+	KeyP       Key = 80
+	KeyQ       Key = 81
+	KeyR       Key = 82
+	KeyS       Key = 83
+	KeyT       Key = 84
+	KeyU       Key = 85
+	KeyV       Key = 86
+	KeyW       Key = 87
+	KeyX       Key = 88
+	KeyY       Key = 89
+	KeyZ       Key = 90
+
+	KeyEscape    Key = 256
+	KeyEnter     Key = 257
+	KeyTab       Key = 258
+	KeyBackspace Key = 259
+	KeyInsert    Key = 260
+	KeyDelete    Key = 261
+	KeyRight     Key = 262
+	KeyLeft      Key = 263
+	KeyDown      Key = 264
+	KeyUp        Key = 265
+	KeyPageUp    Key = 266
+	KeyPageDown  Key = 267
+	KeyHome      Key = 268
+	KeyEnd       Key = 269
+
+	KeyF1  Key = 290
+	KeyF2  Key = 291
+	KeyF3  Key = 292
+	KeyF4  Key = 293
+	KeyF5  Key = 294
+	KeyF6  Key = 295
+	KeyF7  Key = 296
+	KeyF8  Key = 297
+	KeyF9  Key = 298
+	KeyF10 Key = 299
+	KeyF11 Key = 300
+	KeyF12 Key = 301
+
+	KeyLeftShift    Key = 340
+	KeyLeftControl  Key = 341
+	KeyLeftAlt      Key = 342
+	KeyLeftSuper    Key = 343
+	KeyRightShift   Key = 344
+	KeyRightControl Key = 345
+	KeyRightAlt     Key = 346
+	KeyRightSuper   Key = 347
+)
+
+type KeyEvent struct {
+	Key    Key
+	Action Action
+	Mods   Modifier
+}
+
+// CharEvent is a character typed with the keyboard.
+type CharEvent struct {
+	Char rune
+}
+
+type MouseButtonEvent struct {
+	Button MouseButton
+	Action Action
+	Mods   Modifier
+	X, Y   float64
+}
+
+type MouseMoveEvent struct {
+	X, Y float64
+}
+
+// ScrollEvent is a movement of the mouse wheel or the touchpad, positive Y
+// is up.
+type ScrollEvent struct {
+	X, Y float64
+}
+
+// ResizeEvent reports new size of the window. The layergl viewport is
+// already updated when it is received.
+type ResizeEvent struct {
+	Width, Height int
+}
+
+// CloseEvent is a request to close the window. The loop stops after it,
+// unless the handler calls SetShouldClose(false).
+type CloseEvent struct{}
@@ -0,0 +1,64 @@
+package app
+
+// FakeWindow is a Window without a display, driven by queued events. It
+// closes after MaxFrames frames, if set.
+type FakeWindow struct {
+	Width, Height int
+	Title         string
+	MaxFrames     int
+
+	// Number of frames swapped so far.
+	Frames int
+
+	closed bool
+	queue  [][]Event
+}
+
+func NewFakeWindow(width, height int) *FakeWindow {
+	return &FakeWindow{Width: width, Height: height}
+}
+
+// Queues events delivered together by one PollEvents call.
+func (w *FakeWindow) Push(events ...Event) {
+	w.queue = append(w.queue, events)
+}
+
+func (w *FakeWindow) Size() (width, height int) {
+	return w.Width, w.Height
+}
+
+func (w *FakeWindow) ShouldClose() bool {
+	return w.closed || (w.MaxFrames > 0 && w.Frames >= w.MaxFrames)
+}
+
+func (w *FakeWindow) SetShouldClose(value bool) {
+	w.closed = value
+}
+
+func (w *FakeWindow) SetTitle(title string) {
+	w.Title = title
+}
+
+func (w *FakeWindow) PollEvents() []Event {
+	if len(w.queue) == 0 {
+		return nil
+	}
+
+	events := w.queue[0]
+	w.queue = w.queue[1:]
+	for _, e := range events {
+		if _, ok := e.(CloseEvent); ok {
+			w.closed = true
+		}
+		if r, ok := e.(ResizeEvent); ok {
+			w.Width, w.Height = r.Width, r.Height
+		}
+	}
+	return events
+}
+
+func (w *FakeWindow) SwapBuffers() {
+	w.Frames++
+}
+
+func (w *FakeWindow) Destroy() {}
@@ -0,0 +1,159 @@
+// Package glfwapp implements app.Window with GLFW. It is kept apart from
+// package app, so that App, Loop and FakeWindow build without cgo.
+package glfwapp
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/iostapyshyn/layergl"
+	"github.com/iostapyshyn/layergl/app"
+	"runtime"
+)
+
+// GLFW and OpenGL calls must be made from the main thread.
+func init() {
+	runtime.LockOSThread()
+}
+
+// Config of the window opened by Open.
+type Config struct {
+	Title         string
+	Width, Height int
+
+	// Number of samples of multisample anti-aliasing, 0 disables it.
+	Samples int
+
+	// Synchronize buffer swaps with the display refresh.
+	VSync bool
+
+	Resizable bool
+}
+
+type glfwWindow struct {
+	window *glfw.Window
+	events []app.Event
+}
+
+// Opens centered window with OpenGL 3.3 core context and initializes layergl.
+// Must be called from the main goroutine.
+func Open(config Config) (*app.App, error) {
+	if err := glfw.Init(); err != nil {
+		return nil, err
+	}
+
+	// OpenGL version 3.3 Core.
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+
+	glfw.WindowHint(glfw.Samples, config.Samples)
+
+	resizable := glfw.False
+	if config.Resizable {
+		resizable = glfw.True
+	}
+	glfw.WindowHint(glfw.Resizable, resizable)
+	glfw.WindowHint(glfw.Visible, glfw.False)
+
+	window, err := glfw.CreateWindow(config.Width, config.Height, config.Title, nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		return nil, err
+	}
+
+	// Center window on the screen.
+	vidmode := glfw.GetPrimaryMonitor().GetVideoMode()
+	window.SetPos((vidmode.Width-config.Width)/2, (vidmode.Height-config.Height)/2)
+
+	window.MakeContextCurrent()
+	if config.VSync {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
+
+	// The framebuffer is larger than the window on HiDPI displays.
+	width, height := window.GetFramebufferSize()
+	if err := layergl.Init(width, height); err != nil {
+		window.Destroy()
+		glfw.Terminate()
+		return nil, err
+	}
+
+	w := &glfwWindow{window: window}
+	w.setCallbacks()
+	window.Show()
+
+	return app.New(w), nil
+}
+
+func (w *glfwWindow) setCallbacks() {
+	w.window.SetKeyCallback(func(_ *glfw.Window, key glfw.Key, _ int, action glfw.Action, mods glfw.ModifierKey) {
+		w.events = append(w.events, app.KeyEvent{Key: app.Key(key), Action: app.Action(action), Mods: app.Modifier(mods)})
+	})
+	w.window.SetCharCallback(func(_ *glfw.Window, char rune) {
+		w.events = append(w.events, app.CharEvent{Char: char})
+	})
+	w.window.SetMouseButtonCallback(func(_ *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		x, y := w.toPixels(w.window.GetCursorPos())
+		w.events = append(w.events, app.MouseButtonEvent{Button: app.MouseButton(button), Action: app.Action(action), Mods: app.Modifier(mods), X: x, Y: y})
+	})
+	w.window.SetCursorPosCallback(func(_ *glfw.Window, x, y float64) {
+		x, y = w.toPixels(x, y)
+		w.events = append(w.events, app.MouseMoveEvent{X: x, Y: y})
+	})
+	w.window.SetScrollCallback(func(_ *glfw.Window, x, y float64) {
+		w.events = append(w.events, app.ScrollEvent{X: x, Y: y})
+	})
+	w.window.SetFramebufferSizeCallback(func(_ *glfw.Window, width, height int) {
+		layergl.Resize(width, height)
+		w.events = append(w.events, app.ResizeEvent{Width: width, Height: height})
+	})
+	w.window.SetCloseCallback(func(_ *glfw.Window) {
+		w.events = append(w.events, app.CloseEvent{})
+	})
+}
+
+// Converts cursor position from screen coordinates to framebuffer pixels.
+// They differ on HiDPI displays.
+func (w *glfwWindow) toPixels(x, y float64) (float64, float64) {
+	ww, wh := w.window.GetSize()
+	fw, fh := w.window.GetFramebufferSize()
+	if ww == 0 || wh == 0 {
+		return x, y
+	}
+	return x * float64(fw) / float64(ww), y * float64(fh) / float64(wh)
+}
+
+func (w *glfwWindow) Size() (width, height int) {
+	return w.window.GetFramebufferSize()
+}
+
+func (w *glfwWindow) ShouldClose() bool {
+	return w.window.ShouldClose()
+}
+
+func (w *glfwWindow) SetShouldClose(value bool) {
+	w.window.SetShouldClose(value)
+}
+
+func (w *glfwWindow) SetTitle(title string) {
+	w.window.SetTitle(title)
+}
+
+func (w *glfwWindow) PollEvents() []app.Event {
+	w.events = w.events[:0]
+	glfw.PollEvents()
+	return w.events
+}
+
+func (w *glfwWindow) SwapBuffers() {
+	w.window.SwapBuffers()
+}
+
+// Releases layergl objects, reporting leaks, and closes the window.
+func (w *glfwWindow) Destroy() {
+	layergl.Terminate()
+	w.window.Destroy()
+	glfw.Terminate()
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"time"
+)
+
+// Loop calls Update with a fixed timestep, independently of the frame rate.
+// Time elapsed between frames is accumulated and consumed in steps of Step;
+// the remainder is reported by Alpha for interpolating the drawn state
+// between the previous and the current update. Loop does not use the window,
+// replacing Now makes it deterministic in tests.
+type Loop struct {
+	// Duration of one update.
+	Step time.Duration
+
+	// Maximum number of updates run per frame. If the updates fall further
+	// behind, the rest of the elapsed time is dropped instead of trying to
+	// catch up. Zero means no limit.
+	MaxSteps int
+
+	Update func(dt time.Duration)
+
+	// Returns the current time, time.Now by default.
+	Now func() time.Time
+
+	last    time.Time
+	started bool
+	lag     time.Duration
+	paused  bool
+	ticks   uint64
+}
+
+// Creates Loop running update every step, with at most 5 updates per frame.
+func NewLoop(step time.Duration, update func(dt time.Duration)) *Loop {
+	return &Loop{Step: step, MaxSteps: 5, Update: update, Now: time.Now}
+}
+
+// Advances the loop by the time since the previous Tick and returns the
+// number of updates run. The first Tick only starts the clock.
+func (l *Loop) Tick() int {
+	now := l.now()
+	if !l.started {
+		l.last, l.started = now, true
+		return 0
+	}
+
+	elapsed := now.Sub(l.last)
+	l.last = now
+	return l.Advance(elapsed)
+}
+
+// Advances the loop by the elapsed time and returns the number of updates
+// run. Nothing is run while the loop is paused.
+func (l *Loop) Advance(elapsed time.Duration) int {
+	if l.paused || l.Step <= 0 {
+		return 0
+	}
+	if elapsed > 0 {
+		l.lag += elapsed
+	}
+
+	n := 0
+	for l.lag >= l.Step {
+		if l.MaxSteps > 0 && n == l.MaxSteps {
+			l.lag %= l.Step
+			break
+		}
+
+		l.update()
+		l.lag -= l.Step
+		n++
+	}
+
+	return n
+}
+
+// Returns the fraction of the step elapsed since the last update, in the
+// range [0, 1).
+func (l *Loop) Alpha() float64 {
+	if l.Step <= 0 {
+		return 0
+	}
+	return float64(l.lag) / float64(l.Step)
+}
+
+// Returns the number of updates run since the loop was created.
+func (l *Loop) Ticks() uint64 {
+	return l.ticks
+}
+
+// Stops running updates. Time elapsed while paused is not caught up on.
+func (l *Loop) Pause() {
+	l.paused = true
+}
+
+func (l *Loop) Resume() {
+	l.paused = false
+	l.last, l.started = l.now(), true
+}
+
+func (l *Loop) Paused() bool {
+	return l.paused
+}
+
+// Runs a single update while the loop is paused.
+func (l *Loop) StepOnce() {
+	if l.paused {
+		l.update()
+	}
+}
+
+func (l *Loop) now() time.Time {
+	if l.Now == nil {
+		return time.Now()
+	}
+	return l.Now()
+}
+
+func (l *Loop) update() {
+	if l.Update != nil {
+		l.Update(l.Step)
+	}
+	l.ticks++
+}
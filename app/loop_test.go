@@ -0,0 +1,153 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+// Loop driven by a clock moved by the test.
+type testLoop struct {
+	*Loop
+	now     time.Time
+	updates int
+}
+
+func newTestLoop(step time.Duration, maxSteps int) *testLoop {
+	l := &testLoop{now: time.Unix(0, 0)}
+	l.Loop = NewLoop(step, func(dt time.Duration) {
+		if dt != step {
+			panic("update with wrong dt")
+		}
+		l.updates++
+	})
+	l.MaxSteps = maxSteps
+	l.Now = func() time.Time { return l.now }
+	return l
+}
+
+func (l *testLoop) wait(d time.Duration) {
+	l.now = l.now.Add(d)
+}
+
+func TestLoop(t *testing.T) {
+	const ms = time.Millisecond
+
+	// Actions applied to the loop in order: waiting, a tick or a control
+	// call. After each tick the number of updates run by it and the alpha
+	// are checked.
+	type action struct {
+		wait    time.Duration
+		tick    bool
+		control func(*Loop)
+		updates int
+		alpha   float64
+	}
+	tick := func(updates int, alpha float64) action {
+		return action{tick: true, updates: updates, alpha: alpha}
+	}
+	wait := func(d time.Duration) action {
+		return action{wait: d}
+	}
+	control := func(f func(*Loop)) action {
+		return action{control: f}
+	}
+
+	tests := []struct {
+		name     string
+		maxSteps int
+		actions  []action
+		total    int
+	}{
+		{
+			name: "first tick starts the clock",
+			actions: []action{
+				wait(time.Hour), tick(0, 0),
+				wait(10 * ms), tick(1, 0),
+			},
+			total: 1,
+		},
+		{
+			name: "remainder is carried",
+			actions: []action{
+				tick(0, 0),
+				wait(25 * ms), tick(2, 0.5),
+				wait(4 * ms), tick(0, 0.9),
+				wait(1 * ms), tick(1, 0),
+			},
+			total: 3,
+		},
+		{
+			name:     "max steps drop lag",
+			maxSteps: 3,
+			actions: []action{
+				tick(0, 0),
+				wait(1005 * ms), tick(3, 0.5),
+				wait(10 * ms), tick(1, 0.5),
+			},
+			total: 4,
+		},
+		{
+			name: "no limit catches up",
+			actions: []action{
+				tick(0, 0),
+				wait(1005 * ms), tick(100, 0.5),
+			},
+			total: 100,
+		},
+		{
+			name: "pause does not catch up",
+			actions: []action{
+				tick(0, 0),
+				wait(15 * ms), tick(1, 0.5),
+				control((*Loop).Pause),
+				wait(time.Second), tick(0, 0.5),
+				wait(time.Second),
+				control((*Loop).Resume),
+				wait(5 * ms), tick(1, 0),
+			},
+			total: 2,
+		},
+		{
+			name: "step once only while paused",
+			actions: []action{
+				tick(0, 0),
+				control((*Loop).StepOnce),
+				control((*Loop).Pause),
+				control((*Loop).StepOnce),
+				control((*Loop).StepOnce),
+				wait(time.Second), tick(0, 0),
+			},
+			total: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l := newTestLoop(10*ms, test.maxSteps)
+			for i, a := range test.actions {
+				l.wait(a.wait)
+				if a.control != nil {
+					a.control(l.Loop)
+				}
+				if !a.tick {
+					continue
+				}
+
+				if n := l.Tick(); n != a.updates {
+					t.Fatalf("action %d: %d updates, want %d", i, n, a.updates)
+				}
+				alpha := l.Alpha()
+				if alpha < 0 || alpha >= 1 {
+					t.Fatalf("action %d: alpha %v out of [0, 1)", i, alpha)
+				}
+				if diff := alpha - a.alpha; diff < -1e-9 || diff > 1e-9 {
+					t.Fatalf("action %d: alpha %v, want %v", i, alpha, a.alpha)
+				}
+			}
+
+			if l.updates != test.total || l.Ticks() != uint64(test.total) {
+				t.Errorf("%d updates, %d ticks, want %d", l.updates, l.Ticks(), test.total)
+			}
+		})
+	}
+}
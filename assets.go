@@ -0,0 +1,84 @@
+package layergl
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AssetLoader resolves textures and fonts referenced by scene files. Loaders
+// may return the same object for repeated requests; scenes do not delete
+// assets.
+type AssetLoader interface {
+	LoadTexture(path string) (*Texture, error)
+	LoadFont(path string, scale int32) (*Font, error)
+}
+
+// FileLoader loads assets from files relative to Dir, once per path.
+type FileLoader struct {
+	Dir string
+
+	textures map[string]*Texture
+	fonts    map[fontKey]*Font
+}
+
+type fontKey struct {
+	path  string
+	scale int32
+}
+
+func NewFileLoader(dir string) *FileLoader {
+	return &FileLoader{
+		Dir:      dir,
+		textures: make(map[string]*Texture),
+		fonts:    make(map[fontKey]*Font),
+	}
+}
+
+func (l *FileLoader) LoadTexture(path string) (*Texture, error) {
+	if t, ok := l.textures[path]; ok {
+		return t, nil
+	}
+
+	fd, err := os.Open(filepath.Join(l.Dir, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	t, err := NewTextureFromReader(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Path = path
+	l.textures[path] = t
+	return t, nil
+}
+
+func (l *FileLoader) LoadFont(path string, scale int32) (*Font, error) {
+	key := fontKey{path, scale}
+	if f, ok := l.fonts[key]; ok {
+		return f, nil
+	}
+
+	f, err := LoadFont(filepath.Join(l.Dir, filepath.FromSlash(path)), scale)
+	if err != nil {
+		return nil, err
+	}
+
+	f.path = path
+	l.fonts[key] = f
+	return f, nil
+}
+
+// Deletes all loaded assets.
+func (l *FileLoader) Delete() {
+	for path, t := range l.textures {
+		t.Delete()
+		delete(l.textures, path)
+	}
+	for key, f := range l.fonts {
+		f.Delete()
+		delete(l.fonts, key)
+	}
+}
@@ -0,0 +1,269 @@
+package layergl
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// AtlasBuilder packs many images into a few shared textures (pages), so that
+// they can be drawn without switching textures.
+type AtlasBuilder struct {
+	// Width and height of the pages in pixels.
+	PageSize int
+
+	// Empty pixels between the images.
+	Padding int
+
+	// Number of times the edge pixels of each image are repeated around it,
+	// preventing neighbouring images from bleeding in when filtering.
+	Extrude int
+
+	// Sampling options of the pages.
+	Options TextureOptions
+
+	images []atlasImage
+}
+
+type atlasImage struct {
+	name string
+	img  image.Image
+}
+
+// Atlas is the result of AtlasBuilder.Build.
+type Atlas struct {
+	Pages []*Texture
+
+	textures map[string]*Texture
+}
+
+// Creates new AtlasBuilder with square pages of the given size, 1 pixel of
+// extrusion and 1 pixel of padding.
+func NewAtlasBuilder(pageSize int) *AtlasBuilder {
+	return &AtlasBuilder{PageSize: pageSize, Padding: 1, Extrude: 1}
+}
+
+// Adds the image to the atlas under the name.
+func (b *AtlasBuilder) Add(name string, img image.Image) {
+	b.images = append(b.images, atlasImage{name, img})
+}
+
+// Packs the images and uploads the pages.
+func (b *AtlasBuilder) Build() (*Atlas, error) {
+	pages, placements, err := b.pack()
+	if err != nil {
+		return nil, err
+	}
+
+	atlas := &Atlas{textures: make(map[string]*Texture, len(b.images))}
+	for _, page := range pages {
+		tex := new(Texture)
+		tex.VertexObject = Rectangle(Rect{0, 0, float64(b.PageSize), float64(b.PageSize)})
+		tex.width = float32(b.PageSize)
+		tex.height = float32(b.PageSize)
+		tex.options = b.Options
+		tex.tex, tex.size, err = uploadImage(page, b.Options)
+		if err != nil {
+			atlas.Delete()
+			return nil, err
+		}
+		tex.res = trackTexture("atlas page", tex.tex)
+
+		atlas.Pages = append(atlas.Pages, tex)
+	}
+
+	for i, p := range placements {
+		r := p.region
+		atlas.textures[b.images[i].name] = atlas.Pages[p.page].SubTexture(r, float64(r.Width), float64(r.Height))
+	}
+
+	return atlas, nil
+}
+
+type atlasPlacement struct {
+	page   int
+	region TextureRegion
+}
+
+// Places the images on as few pages as possible and draws them.
+func (b *AtlasBuilder) pack() ([]*image.RGBA, []atlasPlacement, error) {
+	border := b.Extrude + b.Padding
+
+	// Tallest images first give tighter skylines.
+	order := make([]int, len(b.images))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return b.images[order[i]].img.Bounds().Dy() > b.images[order[j]].img.Bounds().Dy()
+	})
+
+	var skylines []*skyline
+	var pages []*image.RGBA
+	placements := make([]atlasPlacement, len(b.images))
+
+	for _, i := range order {
+		img := b.images[i].img
+		size := img.Bounds().Size()
+		w, h := size.X+2*border, size.Y+2*border
+
+		if w > b.PageSize || h > b.PageSize {
+			return nil, nil, fmt.Errorf("atlas: image %q (%dx%d) does not fit into %dx%d page",
+				b.images[i].name, size.X, size.Y, b.PageSize, b.PageSize)
+		}
+
+		page := -1
+		var x, y int
+		for p, s := range skylines {
+			var ok bool
+			if x, y, ok = s.insert(w, h); ok {
+				page = p
+				break
+			}
+		}
+
+		if page == -1 {
+			s := newSkyline(b.PageSize, b.PageSize)
+			skylines = append(skylines, s)
+			pages = append(pages, image.NewRGBA(image.Rect(0, 0, b.PageSize, b.PageSize)))
+			page = len(pages) - 1
+			x, y, _ = s.insert(w, h)
+		}
+
+		region := TextureRegion{x + border, y + border, size.X, size.Y}
+		placements[i] = atlasPlacement{page, region}
+		drawExtruded(pages[page], img, region, b.Extrude)
+	}
+
+	return pages, placements, nil
+}
+
+// Draws the image into the region of the page, repeating its edge pixels
+// extrude times around it.
+func drawExtruded(page *image.RGBA, img image.Image, region TextureRegion, extrude int) {
+	src := img.Bounds()
+	dst := image.Rect(region.X, region.Y, region.X+region.Width, region.Y+region.Height)
+	draw.Draw(page, dst, img, src.Min, draw.Src)
+
+	if extrude <= 0 || dst.Empty() {
+		return
+	}
+
+	// Top and bottom rows first, then the columns including the corners.
+	for i := 1; i <= extrude; i++ {
+		draw.Draw(page, image.Rect(dst.Min.X, dst.Min.Y-i, dst.Max.X, dst.Min.Y-i+1), page, dst.Min, draw.Src)
+		draw.Draw(page, image.Rect(dst.Min.X, dst.Max.Y+i-1, dst.Max.X, dst.Max.Y+i), page, image.Pt(dst.Min.X, dst.Max.Y-1), draw.Src)
+	}
+	for i := 1; i <= extrude; i++ {
+		draw.Draw(page, image.Rect(dst.Min.X-i, dst.Min.Y-extrude, dst.Min.X-i+1, dst.Max.Y+extrude), page, image.Pt(dst.Min.X, dst.Min.Y-extrude), draw.Src)
+		draw.Draw(page, image.Rect(dst.Max.X+i-1, dst.Min.Y-extrude, dst.Max.X+i, dst.Max.Y+extrude), page, image.Pt(dst.Max.X-1, dst.Min.Y-extrude), draw.Src)
+	}
+}
+
+// Returns texture of the image added under the name, or nil. The texture
+// has the size of the image and shares the GL texture of its page.
+func (a *Atlas) Texture(name string) *Texture {
+	t, ok := a.textures[name]
+	if !ok {
+		return nil
+	}
+
+	// Copy, so that the geometry of the returned texture can be changed.
+	return t.SubTexture(t.Region, float64(t.width), float64(t.height))
+}
+
+// Deletes the pages. Textures of the atlas must not be drawn afterwards.
+func (a *Atlas) Delete() {
+	for _, page := range a.Pages {
+		page.Delete()
+	}
+}
+
+// Skyline bottom-left rectangle packer.
+type skyline struct {
+	width, height int
+	nodes         []skylineNode
+}
+
+// Horizontal segment of the skyline, y is the first free row.
+type skylineNode struct {
+	x, y, w int
+}
+
+func newSkyline(width, height int) *skyline {
+	return &skyline{width, height, []skylineNode{{0, 0, width}}}
+}
+
+// Finds position for the rectangle with the lowest top edge and reserves it.
+func (s *skyline) insert(w, h int) (x, y int, ok bool) {
+	best, bestY, bestW := -1, 0, 0
+	for i := range s.nodes {
+		y, fits := s.fit(i, w, h)
+		if !fits {
+			continue
+		}
+
+		if best == -1 || y < bestY || (y == bestY && s.nodes[i].w < bestW) {
+			best, bestY, bestW = i, y, s.nodes[i].w
+		}
+	}
+
+	if best == -1 {
+		return 0, 0, false
+	}
+
+	x = s.nodes[best].x
+	s.add(best, skylineNode{x, bestY + h, w})
+	return x, bestY, true
+}
+
+// Returns y at which the rectangle placed at node i would rest.
+func (s *skyline) fit(i, w, h int) (int, bool) {
+	x := s.nodes[i].x
+	if x+w > s.width {
+		return 0, false
+	}
+
+	y := 0
+	for remaining := w; remaining > 0; i++ {
+		if s.nodes[i].y > y {
+			y = s.nodes[i].y
+		}
+		remaining -= s.nodes[i].w
+	}
+
+	return y, y+h <= s.height
+}
+
+// Inserts the node at index i, cutting the nodes it covers.
+func (s *skyline) add(i int, n skylineNode) {
+	s.nodes = append(s.nodes, skylineNode{})
+	copy(s.nodes[i+1:], s.nodes[i:])
+	s.nodes[i] = n
+
+	for j := i + 1; j < len(s.nodes); {
+		prev := s.nodes[j-1]
+		if s.nodes[j].x >= prev.x+prev.w {
+			break
+		}
+
+		shrink := prev.x + prev.w - s.nodes[j].x
+		s.nodes[j].x += shrink
+		s.nodes[j].w -= shrink
+		if s.nodes[j].w > 0 {
+			break
+		}
+		s.nodes = append(s.nodes[:j], s.nodes[j+1:]...)
+	}
+
+	// Merge neighbours of the same height.
+	for j := 0; j+1 < len(s.nodes); {
+		if s.nodes[j].y == s.nodes[j+1].y {
+			s.nodes[j].w += s.nodes[j+1].w
+			s.nodes = append(s.nodes[:j+1], s.nodes[j+2:]...)
+		} else {
+			j++
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package layergl
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSkylineNoOverlap(t *testing.T) {
+	s := newSkyline(64, 64)
+	rng := rand.New(rand.NewSource(1))
+
+	var placed []image.Rectangle
+	for i := 0; i < 200; i++ {
+		w, h := 1+rng.Intn(12), 1+rng.Intn(12)
+		x, y, ok := s.insert(w, h)
+		if !ok {
+			continue
+		}
+
+		r := image.Rect(x, y, x+w, y+h)
+		if !r.In(image.Rect(0, 0, 64, 64)) {
+			t.Fatalf("%v is outside of the page", r)
+		}
+		for _, p := range placed {
+			if r.Overlaps(p) {
+				t.Fatalf("%v overlaps %v", r, p)
+			}
+		}
+		placed = append(placed, r)
+	}
+
+	if len(placed) < 40 {
+		t.Errorf("only %d rectangles placed", len(placed))
+	}
+}
+
+func TestSkylineFull(t *testing.T) {
+	s := newSkyline(10, 10)
+	if _, _, ok := s.insert(11, 1); ok {
+		t.Error("rectangle wider than the page was placed")
+	}
+	if x, y, ok := s.insert(10, 10); !ok || x != 0 || y != 0 {
+		t.Fatalf("page-sized rectangle placed at %d, %d, %v", x, y, ok)
+	}
+	if x, y, ok := s.insert(1, 1); ok {
+		t.Errorf("rectangle placed at %d, %d on a full page", x, y)
+	}
+}
+
+func TestSkylineMerge(t *testing.T) {
+	s := newSkyline(10, 10)
+
+	steps := []struct {
+		w, h  int
+		x, y  int
+		nodes []skylineNode
+	}{
+		{4, 2, 0, 0, []skylineNode{{0, 2, 4}, {4, 0, 6}}},
+		// Rests on the lower segment, then levels with the first one.
+		{6, 2, 4, 0, []skylineNode{{0, 2, 10}}},
+		{3, 5, 0, 2, []skylineNode{{0, 7, 3}, {3, 2, 7}}},
+		// Covers part of the lower segment and rests on it.
+		{5, 1, 3, 2, []skylineNode{{0, 7, 3}, {3, 3, 5}, {8, 2, 2}}},
+		// Spans all segments and rests on the highest one.
+		{10, 1, 0, 7, []skylineNode{{0, 8, 10}}},
+	}
+	for i, step := range steps {
+		x, y, ok := s.insert(step.w, step.h)
+		if !ok || x != step.x || y != step.y {
+			t.Fatalf("step %d: %dx%d placed at %d, %d, %v, want %d, %d", i, step.w, step.h, x, y, ok, step.x, step.y)
+		}
+		if !reflect.DeepEqual(s.nodes, step.nodes) {
+			t.Fatalf("step %d: skyline %v, want %v", i, s.nodes, step.nodes)
+		}
+	}
+}
+
+func filledImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+func TestAtlasPackPadding(t *testing.T) {
+	b := NewAtlasBuilder(64)
+	b.Padding, b.Extrude = 2, 1
+	sizes := []image.Point{{10, 20}, {20, 10}, {8, 8}, {16, 16}, {5, 30}, {12, 3}}
+	for i, size := range sizes {
+		b.Add(string(rune('a'+i)), filledImage(size.X, size.Y))
+	}
+
+	pages, placements, err := b.pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every image reserves its extrusion and padding around it.
+	border := b.Padding + b.Extrude
+	for i, p := range placements {
+		r := p.region
+		if r.Width != sizes[i].X || r.Height != sizes[i].Y {
+			t.Errorf("image %d: region %v, want size %v", i, r, sizes[i])
+		}
+
+		outer := image.Rect(r.X-border, r.Y-border, r.X+r.Width+border, r.Y+r.Height+border)
+		if !outer.In(image.Rect(0, 0, b.PageSize, b.PageSize)) {
+			t.Errorf("image %d: %v with padding leaves the page", i, outer)
+		}
+		for j, q := range placements[:i] {
+			s := q.region
+			other := image.Rect(s.X-border, s.Y-border, s.X+s.Width+border, s.Y+s.Height+border)
+			if p.page == q.page && outer.Overlaps(other) {
+				t.Errorf("images %d and %d are closer than the padding: %v, %v", i, j, outer, other)
+			}
+		}
+
+		// The extruded ring is drawn, the padding around it is empty.
+		page := pages[p.page]
+		extruded := page.RGBAAt(r.X-b.Extrude, r.Y-b.Extrude)
+		padding := page.RGBAAt(r.X-b.Extrude-1, r.Y)
+		if extruded.A != 255 || padding != (color.RGBA{}) {
+			t.Errorf("image %d: extruded pixel %v, padding pixel %v", i, extruded, padding)
+		}
+	}
+}
+
+func TestAtlasPackPages(t *testing.T) {
+	b := NewAtlasBuilder(32)
+	for i := 0; i < 3; i++ {
+		b.Add(string(rune('a'+i)), filledImage(20, 20))
+	}
+
+	pages, placements, err := b.pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("%d pages, want 3", len(pages))
+	}
+	for i, p := range placements {
+		if p.page != i || p.region != (TextureRegion{2, 2, 20, 20}) {
+			t.Errorf("image %d: page %d, region %v", i, p.page, p.region)
+		}
+	}
+}
+
+func TestAtlasPackTooLarge(t *testing.T) {
+	b := NewAtlasBuilder(32)
+	b.Add("small", filledImage(4, 4))
+	// Fits the page, but not with the padding and extrusion.
+	b.Add("large", filledImage(30, 4))
+
+	if _, _, err := b.pack(); err == nil {
+		t.Error("image larger than the page was packed")
+	}
+}
@@ -0,0 +1,68 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Factor of the blending equation, see glBlendFuncSeparate.
+type BlendFactor uint32
+
+const (
+	FactorZero             BlendFactor = gl.ZERO
+	FactorOne              BlendFactor = gl.ONE
+	FactorSrcColor         BlendFactor = gl.SRC_COLOR
+	FactorOneMinusSrcColor BlendFactor = gl.ONE_MINUS_SRC_COLOR
+	FactorDstColor         BlendFactor = gl.DST_COLOR
+	FactorOneMinusDstColor BlendFactor = gl.ONE_MINUS_DST_COLOR
+	FactorSrcAlpha         BlendFactor = gl.SRC_ALPHA
+	FactorOneMinusSrcAlpha BlendFactor = gl.ONE_MINUS_SRC_ALPHA
+	FactorDstAlpha         BlendFactor = gl.DST_ALPHA
+	FactorOneMinusDstAlpha BlendFactor = gl.ONE_MINUS_DST_ALPHA
+)
+
+// BlendMode describes how drawn pixels are combined with the framebuffer,
+// with separate factors for the color and alpha channels. The zero value is
+// not a valid mode; Material uses it to mean "the current mode".
+type BlendMode struct {
+	SrcRGB, DstRGB     BlendFactor
+	SrcAlpha, DstAlpha BlendFactor
+}
+
+var (
+	// Standard alpha blending of non-premultiplied colors. Default.
+	BlendAlpha = BlendMode{FactorSrcAlpha, FactorOneMinusSrcAlpha, FactorSrcAlpha, FactorOneMinusSrcAlpha}
+
+	// Alpha blending of premultiplied colors.
+	BlendPremultiplied = BlendMode{FactorOne, FactorOneMinusSrcAlpha, FactorOne, FactorOneMinusSrcAlpha}
+
+	// Adds colors together, for particles and lights.
+	BlendAdditive = BlendMode{FactorSrcAlpha, FactorOne, FactorZero, FactorOne}
+
+	// Multiplies colors, darkening the framebuffer.
+	BlendMultiply = BlendMode{FactorDstColor, FactorOneMinusSrcAlpha, FactorZero, FactorOne}
+
+	// Inverse of multiply, lightening the framebuffer.
+	BlendScreen = BlendMode{FactorOne, FactorOneMinusSrcColor, FactorZero, FactorOne}
+
+	// Overwrites the framebuffer, blending is disabled.
+	BlendReplace = BlendMode{FactorOne, FactorZero, FactorOne, FactorZero}
+)
+
+// Returns custom BlendMode with separate factors for color and alpha.
+func CustomBlend(srcRGB, dstRGB, srcAlpha, dstAlpha BlendFactor) BlendMode {
+	return BlendMode{srcRGB, dstRGB, srcAlpha, dstAlpha}
+}
+
+// Blend mode used by the following draw calls.
+var blendMode = BlendAlpha
+
+// Sets the blend mode for all following draw calls.
+func SetBlendMode(mode BlendMode) {
+	blendMode = mode
+	state.setBlend(mode)
+}
+
+// Returns the current blend mode.
+func CurrentBlendMode() BlendMode {
+	return blendMode
+}
@@ -2,18 +2,24 @@ package layergl
 
 import (
 	"github.com/go-gl/gl/v3.3-core/gl"
-	"log"
 )
 
 type vertexBuffer struct {
-	vao, vbo, uvbo, ebo uint32
-	vboSize, eboSize    int
-	count               int
+	vao, vbo, uvbo, cbo, ebo            uint32
+	vboSize, uvboSize, cboSize, eboSize int
+	count                               int
+
+	// Whether the color attribute is read from cbo.
+	colors bool
+
+	res *resource
 }
 
 const (
 	t32Bytes = 4
-	uvboSize = 8
+
+	// Initial size of the UV buffer, enough for a quad.
+	uvboInitialSize = 8
 )
 
 func newVertexBuffer(bufferSize int) *vertexBuffer {
@@ -23,11 +29,11 @@ func newVertexBuffer(bufferSize int) *vertexBuffer {
 
 	var vao uint32
 	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
+	state.bindVertexArray(vao)
 
 	var vbo uint32
 	gl.GenBuffers(1, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	state.bindArrayBuffer(vbo)
 	gl.BufferData(gl.ARRAY_BUFFER, bufferSize*t32Bytes, gl.Ptr(nil), gl.DYNAMIC_DRAW)
 
 	// vert attribute
@@ -36,59 +42,188 @@ func newVertexBuffer(bufferSize int) *vertexBuffer {
 
 	var uvbo uint32
 	gl.GenBuffers(1, &uvbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, uvbo)
-	gl.BufferData(gl.ARRAY_BUFFER, uvboSize*t32Bytes, gl.Ptr(nil), gl.DYNAMIC_DRAW)
+	state.bindArrayBuffer(uvbo)
+	gl.BufferData(gl.ARRAY_BUFFER, uvboInitialSize*t32Bytes, gl.Ptr(nil), gl.DYNAMIC_DRAW)
 
 	// texCoord attribute
 	gl.EnableVertexAttribArray(1)
 	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 0, nil)
 
+	var cbo uint32
+	gl.GenBuffers(1, &cbo)
+	state.bindArrayBuffer(cbo)
+	gl.BufferData(gl.ARRAY_BUFFER, bufferSize*2*t32Bytes, gl.Ptr(nil), gl.DYNAMIC_DRAW)
+
+	// color attribute, enabled only when colors are loaded
+	gl.VertexAttribPointer(2, 4, gl.FLOAT, false, 0, nil)
+	gl.VertexAttrib4f(2, 1, 1, 1, 1)
+
 	var ebo uint32
 	gl.GenBuffers(1, &ebo)
 	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
 	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, bufferSize*t32Bytes, gl.Ptr(nil), gl.DYNAMIC_DRAW)
 
-	return &vertexBuffer{vao, vbo, uvbo, ebo, bufferSize, bufferSize, 0}
+	v := &vertexBuffer{
+		vao: vao, vbo: vbo, uvbo: uvbo, cbo: cbo, ebo: ebo,
+		vboSize: bufferSize, uvboSize: uvboInitialSize, cboSize: bufferSize * 2, eboSize: bufferSize,
+	}
+	v.res = trackResource("vertex buffer", "", func() {
+		state.deleteVertexArray(vao)
+		for _, buffer := range []uint32{vbo, uvbo, cbo, ebo} {
+			state.deleteBuffer(buffer)
+		}
+	})
+
+	return v
+}
+
+func (v *vertexBuffer) delete() {
+	v.res.release()
+}
+
+func (v *vertexBuffer) loadUVs(uv []float32) {
+	state.bindArrayBuffer(v.uvbo)
+	uploadBuffer(gl.ARRAY_BUFFER, &v.uvboSize, len(uv), uv)
+}
+
+// Loads vertices and elements. Per-vertex colors are disabled until
+// loadColors is called.
+func (v *vertexBuffer) loadVertexArray(vertices []float32, elements []uint32) {
+	state.bindVertexArray(v.vao)
+
+	state.bindArrayBuffer(v.vbo)
+	uploadBuffer(gl.ARRAY_BUFFER, &v.vboSize, len(vertices), vertices)
+
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, v.ebo)
+	uploadBuffer(gl.ELEMENT_ARRAY_BUFFER, &v.eboSize, len(elements), elements)
+
+	if v.colors {
+		gl.DisableVertexAttribArray(2)
+		v.colors = false
+	}
+
+	v.count = len(elements)
 }
 
-func (v vertexBuffer) loadUVs(uv []float32) {
-	if len(uv) > uvboSize {
-		log.Println("Error loading UVs.")
+// Loads RGBA colors of the vertices. Nil colors draw every vertex white.
+func (v *vertexBuffer) loadColors(colors []float32) {
+	state.bindVertexArray(v.vao)
+
+	if colors == nil {
+		if v.colors {
+			gl.DisableVertexAttribArray(2)
+			v.colors = false
+		}
 		return
 	}
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, v.uvbo)
-	gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(uv)*t32Bytes, gl.Ptr(uv))
+	state.bindArrayBuffer(v.cbo)
+	uploadBuffer(gl.ARRAY_BUFFER, &v.cboSize, len(colors), colors)
+
+	if !v.colors {
+		gl.EnableVertexAttribArray(2)
+		v.colors = true
+	}
 }
 
-func (v *vertexBuffer) loadVertexArray(vertices []float32, elements []uint32) {
-	gl.BindVertexArray(v.vao)
+// Uploads slice of length 32-bit elements to the buffer bound to target,
+// growing the buffer if necessary.
+func uploadBuffer(target uint32, size *int, length int, data interface{}) {
+	if length == 0 {
+		return
+	}
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, v.vbo)
-	if len(vertices) > v.vboSize {
-		for len(vertices) > v.vboSize {
-			v.vboSize *= 2
+	if length > *size {
+		for length > *size {
+			*size *= 2
 		}
-		log.Println("Reallocating VBO:", v.vboSize)
-		gl.BufferData(gl.ARRAY_BUFFER, v.vboSize*t32Bytes, gl.Ptr(vertices), gl.DYNAMIC_DRAW)
-	} else {
-		gl.BufferSubData(gl.ARRAY_BUFFER, 0, len(vertices)*t32Bytes, gl.Ptr(vertices))
+		gl.BufferData(target, *size*t32Bytes, nil, gl.DYNAMIC_DRAW)
+		frameStats.Reallocations++
 	}
 
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, v.ebo)
-	if len(elements) > v.eboSize {
-		for len(elements) > v.eboSize {
-			v.eboSize *= 2
+	gl.BufferSubData(target, 0, length*t32Bytes, gl.Ptr(data))
+}
+
+func (v *vertexBuffer) bind() {
+	state.bindVertexArray(v.vao)
+}
+
+// Creates buffer for geometry uploaded once with loadStatic and drawn many
+// times. Storage is allocated on upload.
+func newStaticBuffer(name string) *vertexBuffer {
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+
+	buffers := make([]uint32, 4)
+	gl.GenBuffers(4, &buffers[0])
+
+	v := &vertexBuffer{vao: vao, vbo: buffers[0], uvbo: buffers[1], cbo: buffers[2], ebo: buffers[3]}
+	v.res = trackResource("mesh", name, func() {
+		state.deleteVertexArray(vao)
+		for _, buffer := range buffers {
+			state.deleteBuffer(buffer)
 		}
-		log.Println("Reallocating EBO:", v.eboSize)
-		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, v.eboSize*t32Bytes, gl.Ptr(elements), gl.DYNAMIC_DRAW)
+	})
+
+	state.bindVertexArray(vao)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, v.ebo)
+
+	state.bindArrayBuffer(v.vbo)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 0, nil)
+
+	state.bindArrayBuffer(v.uvbo)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 0, nil)
+
+	state.bindArrayBuffer(v.cbo)
+	gl.VertexAttribPointer(2, 4, gl.FLOAT, false, 0, nil)
+	gl.VertexAttrib4f(2, 1, 1, 1, 1)
+
+	bindInstanceAttributes()
+
+	return v
+}
+
+// Replaces the whole contents of a static buffer. Texture coordinates and
+// colors are optional, the attributes are disabled when they are nil.
+func (v *vertexBuffer) loadStatic(vertices, uvs, colors []float32, elements []uint32) {
+	state.bindVertexArray(v.vao)
+
+	state.bindArrayBuffer(v.vbo)
+	staticData(gl.ARRAY_BUFFER, len(vertices), vertices)
+	v.vboSize = len(vertices)
+
+	state.bindArrayBuffer(v.uvbo)
+	staticData(gl.ARRAY_BUFFER, len(uvs), uvs)
+	if uvs != nil {
+		gl.EnableVertexAttribArray(1)
 	} else {
-		gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, 0, len(elements)*t32Bytes, gl.Ptr(elements))
+		gl.DisableVertexAttribArray(1)
 	}
 
+	state.bindArrayBuffer(v.cbo)
+	staticData(gl.ARRAY_BUFFER, len(colors), colors)
+	v.cboSize = len(colors)
+	v.colors = colors != nil
+	if v.colors {
+		gl.EnableVertexAttribArray(2)
+	} else {
+		gl.DisableVertexAttribArray(2)
+	}
+
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, v.ebo)
+	staticData(gl.ELEMENT_ARRAY_BUFFER, len(elements), elements)
+	v.eboSize = len(elements)
+
 	v.count = len(elements)
 }
 
-func (v *vertexBuffer) bind() {
-	gl.BindVertexArray(v.vao)
+// Allocates the buffer bound to target and fills it with length 32-bit elements.
+func staticData(target uint32, length int, data interface{}) {
+	if length == 0 {
+		gl.BufferData(target, 0, nil, gl.STATIC_DRAW)
+		return
+	}
+
+	gl.BufferData(target, length*t32Bytes, gl.Ptr(data), gl.STATIC_DRAW)
 }
@@ -0,0 +1,302 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"log"
+	"math"
+)
+
+// Scissor rectangle in window pixels.
+type clipRect struct {
+	x, y, w, h int32
+}
+
+var clips []clipRect
+
+// Restricts drawing to the rectangle until the matching PopClip. The
+// rectangle is transformed by the current transformation; if it ends up
+// rotated, its bounding box is used. Nested clips are intersected.
+func PushClip(r Rect) {
+	b := transform.applyRect(r)
+	c := clipRect{
+		x: int32(math.Floor(b.X1)),
+		y: int32(math.Floor(b.Y1)),
+		w: int32(math.Ceil(b.X2) - math.Floor(b.X1)),
+		h: int32(math.Ceil(b.Y2) - math.Floor(b.Y1)),
+	}
+
+	if len(clips) > 0 {
+		c = c.intersect(clips[len(clips)-1])
+	}
+
+	clips = append(clips, c)
+	applyClip()
+}
+
+// Removes the clip set by the last PushClip.
+func PopClip() {
+	if len(clips) == 0 {
+		log.Println("PopClip: clip stack is empty.")
+		return
+	}
+
+	clips = clips[:len(clips)-1]
+	applyClip()
+}
+
+func (c clipRect) intersect(d clipRect) clipRect {
+	x1 := max32(c.x, d.x)
+	y1 := max32(c.y, d.y)
+	x2 := min32(c.x+c.w, d.x+d.w)
+	y2 := min32(c.y+c.h, d.y+d.h)
+
+	return clipRect{x1, y1, max32(x2-x1, 0), max32(y2-y1, 0)}
+}
+
+func applyClip() {
+	if len(clips) == 0 {
+		gl.Disable(gl.SCISSOR_TEST)
+		return
+	}
+
+	c := clips[len(clips)-1]
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(c.x, c.y, c.w, c.h)
+}
+
+func min32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Stencil masks. The context must be created with a stencil buffer of at
+// least 8 bits (the GLFW default).
+//
+// The highest stencil bit marks pixels inside all active masks, the lower
+// seven bits count the winding number while filling paths.
+const (
+	stencilMaskBit = 0x80
+	stencilCounter = 0x7F
+)
+
+// Geometry of an active mask in window coordinates.
+type mask struct {
+	vertices []float32
+	elements []uint32
+}
+
+var masks []mask
+
+// Restricts drawing to the area covered by the triangles of the mask until
+// the matching PopMask. The mask is transformed by the current
+// transformation. Nested masks are intersected.
+func PushMask(m *VertexObject) {
+	va := make([]float32, 0, len(m.Vertices)*2)
+	for _, v := range m.Vertices {
+		p := transform.Apply(v)
+		va = append(va, float32(p.X), float32(p.Y))
+	}
+
+	_, elements := m.vertexArray()
+	masks = append(masks, mask{va, elements})
+
+	beginStencil()
+	drawMask(masks[len(masks)-1], len(masks) > 1)
+	endStencil()
+}
+
+// Removes the mask set by the last PushMask.
+func PopMask() {
+	if len(masks) == 0 {
+		log.Println("PopMask: mask stack is empty.")
+		return
+	}
+
+	masks = masks[:len(masks)-1]
+	rebuildMasks()
+}
+
+// Redraws the stencil contents from the mask stack.
+func rebuildMasks() {
+	beginStencil()
+
+	gl.StencilMask(0xFF)
+	gl.ClearStencil(0)
+	gl.Clear(gl.STENCIL_BUFFER_BIT)
+
+	for i, m := range masks {
+		drawMask(m, i > 0)
+	}
+
+	endStencil()
+}
+
+// Adds the mask to the stencil buffer. If intersect is false the mask bit is
+// simply set, otherwise it is cleared outside of the mask.
+func drawMask(m mask, intersect bool) {
+	if !intersect {
+		gl.StencilMask(stencilMaskBit)
+		gl.StencilFunc(gl.ALWAYS, stencilMaskBit, 0xFF)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+		drawScreenGeometry(m.vertices, m.elements)
+		return
+	}
+
+	// Mark the pixels covered by the mask inside the current area...
+	gl.StencilMask(stencilCounter)
+	gl.StencilFunc(gl.EQUAL, stencilMaskBit|1, stencilMaskBit)
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+	drawScreenGeometry(m.vertices, m.elements)
+
+	// ...then clear the mask bit of unmarked pixels and reset the counter.
+	gl.StencilMask(stencilMaskBit)
+	gl.StencilFunc(gl.EQUAL, stencilMaskBit, stencilMaskBit|1)
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.INVERT)
+	drawScreenGeometry(screenRect().vertexArray())
+
+	gl.StencilMask(stencilCounter)
+	gl.StencilFunc(gl.ALWAYS, 0, 0xFF)
+	gl.StencilOp(gl.ZERO, gl.ZERO, gl.ZERO)
+	drawScreenGeometry(screenRect().vertexArray())
+}
+
+// Prepares for writing to the stencil buffer only. Masks cover the whole
+// window regardless of the clip rectangle.
+func beginStencil() {
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.Enable(gl.STENCIL_TEST)
+	gl.ColorMask(false, false, false, false)
+}
+
+// Restores the state for regular drawing.
+func endStencil() {
+	gl.ColorMask(true, true, true, true)
+	gl.StencilMask(0xFF)
+	applyClip()
+	applyMask()
+}
+
+// Sets the stencil test so that drawing is limited to the active masks.
+func applyMask() {
+	if len(masks) == 0 {
+		gl.Disable(gl.STENCIL_TEST)
+		return
+	}
+
+	gl.Enable(gl.STENCIL_TEST)
+	gl.StencilFunc(gl.EQUAL, stencilMaskBit, stencilMaskBit)
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+}
+
+func screenRect() Rect {
+	return Rect{0, 0, float64(screenWidth), float64(screenHeight)}
+}
+
+// Draws geometry given in window coordinates, ignoring the transformation.
+func drawScreenGeometry(vertices []float32, elements []uint32) {
+	vertBuffer.loadVertexArray(vertices, elements)
+	polygonShader.bind()
+	polygonShader.SetMatrix("projection", screenProjection)
+	polygonShader.SetColor("color", Color{1, 1, 1, 1})
+	vertBuffer.bind()
+
+	gl.DrawElements(gl.TRIANGLES, int32(vertBuffer.count), gl.UNSIGNED_INT, nil)
+	countDraw(gl.TRIANGLES, vertBuffer.count, 1)
+}
+
+// Rule deciding which parts of a self-intersecting path are inside.
+type FillRule int
+
+const (
+	NonZero FillRule = iota
+	EvenOdd
+)
+
+// Fills the closed path, which may be concave or self-intersecting, using the
+// stencil buffer instead of triangulation.
+func FillPath(path []Point, color Color, rule FillRule) {
+	fillPath(path, rule, func() {
+		polygonShader.drawColor(vertBuffer, color)
+	})
+}
+
+// Fills the closed path with the gradient, see FillPath.
+func FillPathGradient(path []Point, g Gradient, rule FillRule) {
+	fillPath(path, rule, func() {
+		g.apply(gradientShader)
+		gradientShader.draw(vertBuffer, gl.TRIANGLES)
+	})
+}
+
+// Stencils the path and calls cover to draw its loaded bounds.
+func fillPath(path []Point, rule FillRule, cover func()) {
+	if len(path) < 3 {
+		return
+	}
+
+	// Triangle fan around the first point.
+	vertices := make([]float32, 0, len(path)*2)
+	for _, p := range path {
+		vertices = append(vertices, float32(p.X), float32(p.Y))
+	}
+	elements := make([]uint32, 0, (len(path)-2)*3)
+	for i := 1; i+1 < len(path); i++ {
+		elements = append(elements, 0, uint32(i), uint32(i+1))
+	}
+
+	inside := len(masks) > 0
+
+	// Count windings of every pixel.
+	gl.Enable(gl.STENCIL_TEST)
+	gl.ColorMask(false, false, false, false)
+	if inside {
+		gl.StencilFunc(gl.EQUAL, stencilMaskBit, stencilMaskBit)
+	} else {
+		gl.StencilFunc(gl.ALWAYS, 0, 0xFF)
+	}
+
+	if rule == EvenOdd {
+		gl.StencilMask(1)
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.INVERT)
+	} else {
+		gl.StencilMask(stencilCounter)
+		gl.StencilOpSeparate(gl.FRONT, gl.KEEP, gl.KEEP, gl.INCR_WRAP)
+		gl.StencilOpSeparate(gl.BACK, gl.KEEP, gl.KEEP, gl.DECR_WRAP)
+	}
+
+	vertBuffer.loadVertexArray(vertices, elements)
+	polygonShader.draw(vertBuffer, gl.TRIANGLES)
+
+	// Cover the bounds, drawing the inside pixels and resetting the counter.
+	gl.ColorMask(true, true, true, true)
+	gl.StencilMask(stencilCounter)
+	gl.StencilOp(gl.ZERO, gl.ZERO, gl.ZERO)
+
+	switch {
+	case rule == EvenOdd && inside:
+		gl.StencilFunc(gl.EQUAL, stencilMaskBit|1, stencilMaskBit|1)
+	case rule == EvenOdd:
+		gl.StencilFunc(gl.EQUAL, 1, 1)
+	case inside:
+		// Mask bit set and non-zero counter.
+		gl.StencilFunc(gl.LESS, stencilMaskBit, 0xFF)
+	default:
+		gl.StencilFunc(gl.NOTEQUAL, 0, stencilCounter)
+	}
+
+	bounds := VertexObject{Vertices: path}.Bounds()
+	vertBuffer.loadVertexArray(bounds.vertexArray())
+	cover()
+
+	gl.StencilMask(0xFF)
+	applyMask()
+}
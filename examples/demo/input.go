@@ -1,16 +1,28 @@
 package main
 
-import "github.com/go-gl/glfw/v3.2/glfw"
+import "github.com/iostapyshyn/layergl/app"
 
-/* Input handler callback:
-   Press ESC to close application
-*/
-func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	switch action {
-	case glfw.Release:
-		switch key {
-		case glfw.KeyEscape:
-			w.SetShouldClose(true)
+// Input handler: press ESC to close application, P to pause or resume the
+// world, N to advance the paused world by one step and F3 to show or hide
+// the frame statistics.
+func handleEvent(a *app.App, e app.Event) {
+	switch e := e.(type) {
+	case app.KeyEvent:
+		if e.Action == app.Release && e.Key == app.KeyEscape {
+			a.Window.SetShouldClose(true)
+		}
+		if e.Action == app.Press && e.Key == app.KeyP {
+			if loop.Paused() {
+				loop.Resume()
+			} else {
+				loop.Pause()
+			}
+		}
+		if e.Action != app.Release && e.Key == app.KeyN {
+			loop.StepOnce()
+		}
+		if e.Action == app.Press && e.Key == app.KeyF3 {
+			showStats = !showStats
 		}
 	}
 }
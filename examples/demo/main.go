@@ -1,110 +1,65 @@
 package main
 
 import (
-	"fmt"
-	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/iostapyshyn/layergl"
-	"runtime"
+	"github.com/iostapyshyn/layergl/app"
+	"github.com/iostapyshyn/layergl/app/glfwapp"
 	"time"
 )
 
-var window *glfw.Window
-var running bool = true
+// Whether the frame statistics are shown, toggled with F3.
+var showStats = true
 
 const (
 	width  = 640
 	height = 480
 )
 
-func init() {
-	runtime.LockOSThread()
-}
-
 func main() {
-	var err error
-	if err := glfw.Init(); err != nil {
-		panic(err)
-	}
-
-	defer glfw.Terminate()
-
-	// OpenGL version 3.3 Core.
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-
-	// Required for MSAA anti-aliasing.
-	glfw.WindowHint(glfw.Samples, 4)
-
-	glfw.WindowHint(glfw.Resizable, glfw.False)
-	glfw.WindowHint(glfw.Visible, glfw.False)
-
-	window, err = glfw.CreateWindow(width, height, "...", nil, nil)
+	a, err := glfwapp.Open(glfwapp.Config{Title: "...", Width: width, Height: height, Samples: 4})
 	if err != nil {
 		panic(err)
 	}
+	defer a.Close()
 
-	defer window.Destroy()
-
-	window.SetKeyCallback(keyCallback)
-
-	// Center window on the screen.
-	vidmode := glfw.GetPrimaryMonitor().GetVideoMode()
-	window.SetPos((vidmode.Width-width)/2, (vidmode.Height-height)/2)
-
-	window.MakeContextCurrent()
-	glfw.SwapInterval(0)
-
-	window.Show()
-
-	loop()
-}
-
-func loop() {
-	err := layergl.Init(width, height)
+	tex, err = layergl.NewTexture("assets/tex.png", 50, 50)
 	if err != nil {
 		panic(err)
 	}
+	defer tex.Delete()
+	tex.Move(570, 410)
 
-	tex, err = layergl.NewTexture("assets/tex.png", 50, 50)
+	bg, err := layergl.NewTexture("assets/sky.png", width, height)
 	if err != nil {
 		panic(err)
 	}
-	tex.Move(570, 410)
+	defer bg.Delete()
 
-	bg, err := layergl.NewTexture("assets/sky.png", width, height)
+	overlay := layergl.NewStatsOverlay(layergl.Point{10, 10})
+	defer overlay.Delete()
 
-	// Ticker for updating the FPS counter.
-	ticker := time.NewTicker(time.Second)
-	frames := 0
+	loop = worldLoop()
 
-	defer ticker.Stop()
+	a.OnEvent = func(e app.Event) {
+		handleEvent(a, e)
+	}
 
-	worldRun()
-	defer worldStop()
+	a.Update = func(time.Duration) {
+		loop.Tick()
+	}
 
-	for !window.ShouldClose() && running {
+	a.Draw = func() {
 		layergl.Clear()
 
 		layergl.DrawTexture(bg)
 
-		mu.Lock()
-
 		layergl.DrawTexture(tex)
-		layergl.DrawVertexObject(rect, rectColor)
+		worldDraw(loop.Alpha())
 
-		mu.Unlock()
-
-		select {
-		case <-ticker.C:
-			window.SetTitle(fmt.Sprintf("%v FPS", frames))
-			frames = 0
-		default:
-			frames++
+		if showStats {
+			overlay.Draw()
 		}
-
-		window.SwapBuffers()
-		glfw.PollEvents()
 	}
+
+	a.Run()
 }
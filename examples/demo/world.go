@@ -2,53 +2,50 @@ package main
 
 import (
 	"github.com/iostapyshyn/layergl"
+	"github.com/iostapyshyn/layergl/app"
+	"math"
 	"math/rand"
-	"sync"
 	"time"
 )
 
-var mu sync.Mutex
-
 const (
 	rectWidth  = 200
 	rectHeight = 100
+
+	// Time between calls of worldUpdate().
+	worldStep = 32768 * time.Microsecond
 )
 
-var rect = layergl.Rectangle(layergl.Rect{300, 200, 300 + rectWidth, 200 + rectHeight})
+// Rectangle centered at the origin, placed by the world state when drawn.
+var rect = layergl.Rectangle(layergl.Rect{-rectWidth / 2, -rectHeight / 2, rectWidth / 2, rectHeight / 2})
 var tex *layergl.Texture
 
+// Loop running worldUpdate(), paused and stepped from the input handler.
+var loop *app.Loop
+
+// Position and rotation of the rectangle.
+type rectState struct {
+	X, Y  float64
+	Angle float64 // in degrees
+}
+
 var (
+	// State after the last and the previous update, interpolated when drawn.
+	current  = rectState{X: 300 + rectWidth/2, Y: 200 + rectHeight/2}
+	previous = current
+
 	angularVelocity = -.3
 	xVelocity       = 1.2
 	yVelocity       = 1.2
 	rectColor       = layergl.Color{}
 )
 
-// Starts new thread calling worldUpdate() every few milliseconds
-func worldRun() {
-	const usec = 32768 // time in microseconds between calls of worldUpdate()
-
+// Creates the loop calling worldUpdate() every worldStep.
+func worldLoop() *app.Loop {
 	worldInit()
-
-	go func() {
-		running = true
-		var timeNow, timeLast, delta int64
-		timeLast = time.Now().UnixNano() / 1000
-		for running {
-			timeNow = time.Now().UnixNano() / 1000
-			delta += timeNow - timeLast
-			timeLast = timeNow
-			if delta >= usec {
-				worldUpdate()
-				delta -= usec
-			}
-		}
-
-	}()
-
-	for !running {
-		// Wait for running flag to set true.
-	}
+	return app.NewLoop(worldStep, func(time.Duration) {
+		worldUpdate()
+	})
 }
 
 // World initialization
@@ -61,13 +58,13 @@ func worldInit() {
 func worldUpdate() {
 	const texRotation = -0.2
 
-	mu.Lock()
-
-	rect.Move(xVelocity, yVelocity)
-	rect.RotateDeg(angularVelocity)
+	previous = current
+	current.X += xVelocity
+	current.Y += yVelocity
+	current.Angle += angularVelocity
 	tex.RotateDeg(texRotation)
 
-	bounds := rect.Bounds()
+	bounds := transformedBounds(current.transform(), rect)
 
 	if (bounds.X2 >= width && xVelocity > 0) ||
 		(bounds.X1 <= 0 && xVelocity < 0) {
@@ -82,10 +79,30 @@ func worldUpdate() {
 		angularVelocity = -angularVelocity
 		rectColor = layergl.Color{rand.Float64(), rand.Float64(), rand.Float64(), 0.9}
 	}
+}
+
+// Draws the rectangle between the previous and the current state.
+func worldDraw(alpha float64) {
+	s := rectState{
+		X:     previous.X + (current.X-previous.X)*alpha,
+		Y:     previous.Y + (current.Y-previous.Y)*alpha,
+		Angle: previous.Angle + (current.Angle-previous.Angle)*alpha,
+	}
+
+	layergl.PushTransform(s.transform())
+	layergl.DrawVertexObject(rect, rectColor)
+	layergl.PopTransform()
+}
 
-	mu.Unlock()
+func (s rectState) transform() layergl.Transform {
+	return layergl.Translation(s.X, s.Y).Mul(layergl.Rotation(s.Angle * math.Pi / 180))
 }
 
-func worldStop() {
-	running = false
+// Returns bounds of the VertexObject after transformation.
+func transformedBounds(t layergl.Transform, v *layergl.VertexObject) layergl.Rect {
+	points := make([]layergl.Point, len(v.Vertices))
+	for i, p := range v.Vertices {
+		points[i] = t.Apply(p)
+	}
+	return layergl.VertexObject{Vertices: points}.Bounds()
 }
@@ -1,19 +1,20 @@
 package main
 
 import (
-	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/iostapyshyn/layergl"
+	"github.com/iostapyshyn/layergl/app"
+	"github.com/iostapyshyn/layergl/app/glfwapp"
 	"log"
-	"runtime"
 )
 
-var window *glfw.Window
+var window *app.App
 
 var polygon = new(layergl.VertexObject)
 var wireframe bool = true
 
 var (
 	mouseButtonHeld = false
+	mouseX, mouseY  float64
 )
 
 const (
@@ -27,21 +28,28 @@ var (
 	wireColor    layergl.Color = layergl.Color{0.2, 0.2, 0.2, 1.0}
 )
 
-func init() {
-	runtime.LockOSThread()
+func handleEvent(e app.Event) {
+	switch e := e.(type) {
+	case app.KeyEvent:
+		keyEvent(e)
+	case app.MouseButtonEvent:
+		mouseEvent(e)
+	case app.MouseMoveEvent:
+		mouseX, mouseY = e.X, e.Y
+	}
 }
 
-func keyCallback(_ *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	switch action {
-	case glfw.Release:
-		switch key {
-		case glfw.KeyEscape:
-			window.SetShouldClose(true)
+func keyEvent(e app.KeyEvent) {
+	switch e.Action {
+	case app.Release:
+		switch e.Key {
+		case app.KeyEscape:
+			window.Window.SetShouldClose(true)
 		}
 
-	case glfw.Press:
-		switch key {
-		case glfw.KeySpace: // Triangulate the polygon.
+	case app.Press:
+		switch e.Key {
+		case app.KeySpace: // Triangulate the polygon.
 			if len(polygon.Indices) == 0 {
 				if err := polygon.Triangulate(); err != nil {
 					log.Println(err)
@@ -51,125 +59,87 @@ func keyCallback(_ *glfw.Window, key glfw.Key, scancode int, action glfw.Action,
 			} else {
 				polygon.Indices = polygon.Indices[:0]
 			}
-		case glfw.KeyW: // Toggle wireframe.
+		case app.KeyW: // Toggle wireframe.
 			wireframe = !wireframe
-		case glfw.KeyC: // Clear.
+		case app.KeyC: // Clear.
 			polygon.Vertices = polygon.Vertices[:0]
 			polygon.Indices = polygon.Indices[:0]
-		case glfw.KeyZ: // Undo.
+		case app.KeyZ: // Undo.
 			if len(polygon.Vertices) > 0 {
 				polygon.Vertices = append(polygon.Vertices[:len(polygon.Vertices)-1], polygon.Vertices[len(polygon.Vertices):]...)
 				polygon.Indices = polygon.Indices[:0]
 			}
 		}
 
-	case glfw.Repeat:
-		switch key {
-		case glfw.KeyZ: // Undo.
-			polygon.Vertices = append(polygon.Vertices[:len(polygon.Vertices)-1], polygon.Vertices[len(polygon.Vertices):]...)
-			polygon.Indices = polygon.Indices[:0]
+	case app.Repeat:
+		switch e.Key {
+		case app.KeyZ: // Undo.
+			if len(polygon.Vertices) > 0 {
+				polygon.Vertices = append(polygon.Vertices[:len(polygon.Vertices)-1], polygon.Vertices[len(polygon.Vertices):]...)
+				polygon.Indices = polygon.Indices[:0]
+			}
 		}
 	}
 }
 
-func mouseCallback(_ *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
-	if button == glfw.MouseButtonLeft && action == glfw.Press {
-		x, y := window.GetCursorPos()
-		polygon.Vertices = append(polygon.Vertices, layergl.Point{X: x, Y: height - y})
+func mouseEvent(e app.MouseButtonEvent) {
+	if e.Button == app.MouseLeft && e.Action == app.Press {
+		polygon.Vertices = append(polygon.Vertices, window.Point(e.X, e.Y))
 		polygon.Indices = polygon.Indices[:0]
 
 		mouseButtonHeld = true
-	} else if button == glfw.MouseButtonLeft && action == glfw.Release {
+	} else if e.Button == app.MouseLeft && e.Action == app.Release {
 		mouseButtonHeld = false
 	}
 }
 
 func main() {
 	var err error
-	if err := glfw.Init(); err != nil {
-		panic(err)
-	}
-
-	defer glfw.Terminate()
-
-	// OpenGL version 3.3 Core.
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-
-	// Required for MSAA anti-aliasing.
-	glfw.WindowHint(glfw.Samples, 4)
-
-	glfw.WindowHint(glfw.Resizable, glfw.False)
-	glfw.WindowHint(glfw.Visible, glfw.False)
-
-	window, err = glfw.CreateWindow(width, height, "Polygon Triangulation", nil, nil)
+	window, err = glfwapp.Open(glfwapp.Config{Title: "Polygon Triangulation", Width: width, Height: height, Samples: 4, VSync: true})
 	if err != nil {
 		panic(err)
 	}
+	defer window.Close()
 
-	defer window.Destroy()
-
-	window.SetKeyCallback(keyCallback)
-	window.SetMouseButtonCallback(mouseCallback)
-
-	// Center window on the screen.
-	vidmode := glfw.GetPrimaryMonitor().GetVideoMode()
-	window.SetPos((vidmode.Width-width)/2, (vidmode.Height-height)/2)
-
-	window.MakeContextCurrent()
-	glfw.SwapInterval(1)
-
-	window.Show()
+	layergl.ClearColor(bgColor)
 
-	loop()
+	window.OnEvent = handleEvent
+	window.Draw = draw
+	window.Run()
 }
 
-func loop() {
-	err := layergl.Init(width, height)
-	if err != nil {
-		panic(err)
+func draw() {
+	// Add points if mouse if being dragged.
+	if mouseButtonHeld {
+		mouseDrag()
 	}
 
-	layergl.ClearColor(bgColor)
-
-	for !window.ShouldClose() {
-		// Add points if mouse if being dragged.
-		if mouseButtonHeld {
-			mouseDrag()
-		}
-
-		layergl.Clear()
+	layergl.Clear()
 
-		// Draw triangulated polygon or preview line if polygon is not in triangulated state.
-		if len(polygon.Indices) >= 3 {
-			layergl.DrawVertexObject(polygon, polygonColor)
-		} else if len(polygon.Vertices) > 0 {
-			for _, p := range polygon.Vertices {
-				layergl.DrawPoint(p, 2, wireColor)
-			}
-
-			layergl.DrawLines(append(polygon.Vertices, polygon.Vertices[0]), wireColor)
+	// Draw triangulated polygon or preview line if polygon is not in triangulated state.
+	if len(polygon.Indices) >= 3 {
+		layergl.DrawVertexObject(polygon, polygonColor)
+	} else if len(polygon.Vertices) > 0 {
+		for _, p := range polygon.Vertices {
+			layergl.DrawPoint(p, 2, wireColor)
 		}
 
-		if wireframe {
-			drawWireframe()
-		}
+		layergl.DrawLines(append(polygon.Vertices, polygon.Vertices[0]), wireColor)
+	}
 
-		window.SwapBuffers()
-		glfw.PollEvents()
+	if wireframe {
+		drawWireframe()
 	}
 }
 
 func mouseDrag() {
-	x, y := window.GetCursorPos()
+	p := window.Point(mouseX, mouseY)
 
 	// Add new point only if the distance from the previous one is greater than 5px
 	if len(polygon.Vertices) != 0 &&
-		(layergl.Distance(polygon.Vertices[len(polygon.Vertices)-1], layergl.Point{x, height - y}) > 5) {
+		(layergl.Distance(polygon.Vertices[len(polygon.Vertices)-1], p) > 5) {
 
-		polygon.Vertices = append(polygon.Vertices, layergl.Point{X: x, Y: height - y})
+		polygon.Vertices = append(polygon.Vertices, p)
 		polygon.Indices = polygon.Indices[:0]
 	}
 }
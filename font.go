@@ -1,15 +1,15 @@
 package layergl
 
 import (
+	"bytes"
 	"fmt"
-	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/golang/freetype"
 	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
 	"golang.org/x/image/math/fixed"
 	"image"
 	"image/draw"
-	"image/jpeg"
 	"io"
 	"io/ioutil"
 	"os"
@@ -21,6 +21,11 @@ type Font struct {
 	char     []*character
 	vao, vbo uint32
 	texture  uint32
+	res      *resource
+
+	// Asset path and size of the font, used to refer to it when saving scenes.
+	path  string
+	scale int32
 }
 
 type character struct {
@@ -30,7 +35,7 @@ type character struct {
 	bH, bV int32
 }
 
-func loadFont(r io.Reader, scale int32) (*Font, error) {
+func loadFont(r io.Reader, scale int32, name string) (*Font, error) {
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
@@ -41,8 +46,16 @@ func loadFont(r io.Reader, scale int32) (*Font, error) {
 		return nil, err
 	}
 
-	f := new(Font)
+	f := &Font{path: name, scale: scale}
 	f.char = make([]*character, 0, maxchar)
+	// Glyph textures are kept apart from f, a finalizer does not run on
+	// objects referenced from their own cleanup.
+	var glyphs []uint32
+	f.res = trackResource("font", name, func() {
+		for _, tex := range glyphs {
+			state.deleteTexture(tex)
+		}
+	})
 
 	for ch := 0; ch < maxchar; ch++ {
 		char := new(character)
@@ -55,6 +68,7 @@ func loadFont(r io.Reader, scale int32) (*Font, error) {
 
 		gBnd, gAdv, ok := ttfFace.GlyphBounds(rune(ch))
 		if ok != true {
+			f.Delete()
 			return nil, fmt.Errorf("The face does not contain a glyph for %v.", rune(ch))
 		}
 
@@ -103,46 +117,73 @@ func loadFont(r io.Reader, scale int32) (*Font, error) {
 
 		_, err := c.DrawString(string(rune(ch)), pt)
 		if err != nil {
+			f.Delete()
 			return nil, err
 		}
 
-		out, err := os.Create("./output.jpg")
+		texture, _, err := uploadImage(rgba, TextureOptions{})
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			f.Delete()
+			return nil, err
 		}
 
-		var opt jpeg.Options
+		char.tex = texture
+		glyphs = append(glyphs, texture)
 
-		opt.Quality = 80
-		// ok, write out the data into the new JPEG file
+		f.char = append(f.char, char)
+	}
 
-		err = jpeg.Encode(out, rgba, &opt) // put quality to 80%
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+	return f, nil
+}
+
+// Deletes the glyph textures. The font must not be used afterwards.
+func (f *Font) Delete() {
+	f.res.release()
+}
+
+// Returns the size of the text printed at the scale: the advance width and
+// the line height. Unsupported characters are skipped as by Printf.
+func (f *Font) Measure(text string, scale float64) (width, height float64) {
+	for _, r := range text {
+		if int(r) >= len(f.char) {
+			continue
 		}
+		width += float64(f.char[r].adv) / 64 * scale
+	}
 
-		var texture uint32
-		gl.GenTextures(1, &texture)
-		gl.BindTexture(gl.TEXTURE_2D, texture)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-		//		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(rgba.Rect.Dx()), int32(rgba.Rect.Dy()), 0,
-		//			gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
-		gl.TexImage2D(
-			gl.TEXTURE_2D, 0, gl.RGBA,
-			int32(rgba.Rect.Size().X), int32(rgba.Rect.Size().Y), 0,
-			gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+	return width, f.Ascent(scale) + f.Descent(scale)
+}
 
-		char.tex = texture
+// Returns the distance from the baseline to the top of the highest glyph.
+func (f *Font) Ascent(scale float64) float64 {
+	var ascent int32
+	for _, ch := range f.char {
+		if ch.bV > ascent {
+			ascent = ch.bV
+		}
+	}
+	return float64(ascent) * scale
+}
 
-		f.char = append(f.char, char)
+// Returns the distance from the baseline to the bottom of the lowest glyph.
+func (f *Font) Descent(scale float64) float64 {
+	var descent int32
+	for _, ch := range f.char {
+		if ch.h-ch.bV > descent {
+			descent = ch.h - ch.bV
+		}
 	}
+	return float64(descent) * scale
+}
 
-	return f, nil
+// Returns the file the font was loaded from.
+func (f *Font) Path() string {
+	return f.path
+}
+
+// Returns the size the font was loaded at.
+func (f *Font) Scale() int32 {
+	return f.scale
 }
 
 func LoadFont(file string, scale int32) (*Font, error) {
@@ -152,5 +193,11 @@ func LoadFont(file string, scale int32) (*Font, error) {
 	}
 	defer fd.Close()
 
-	return loadFont(fd, scale)
+	return loadFont(fd, scale, file)
+}
+
+// Loads the Go Regular font built into the package, which is available
+// without any asset files.
+func DefaultFont(scale int32) (*Font, error) {
+	return loadFont(bytes.NewReader(goregular.TTF), scale, "")
 }
@@ -21,6 +21,15 @@ type Rect struct {
 type VertexObject struct {
 	Vertices []Point
 	Indices  []int
+
+	// Optional per-vertex colors, multiplied with the color of the draw call.
+	// Used only if there is a color for every vertex.
+	Colors []Color
+
+	// Optional per-vertex texture coordinates used by meshes, with 0, 0 at
+	// the bottom left corner of the texture. Used only if there are
+	// coordinates for every vertex.
+	UVs []Point
 }
 
 // Return distance between two points.
@@ -101,6 +110,36 @@ func (p VertexObject) vertexArray() (va []float32, elements []uint32) {
 	return
 }
 
+// Returns per-vertex colors in a proper format to load into the buffers, or nil
+// if the VertexObject has no colors.
+func (p VertexObject) colorArray() []float32 {
+	if len(p.Colors) == 0 || len(p.Colors) != len(p.Vertices) {
+		return nil
+	}
+
+	ca := make([]float32, 0, len(p.Colors)*4)
+	for _, c := range p.Colors {
+		ca = append(ca, float32(c.R), float32(c.G), float32(c.B), float32(c.A))
+	}
+
+	return ca
+}
+
+// Returns per-vertex texture coordinates in a proper format to load into the
+// buffers, or nil if the VertexObject has no texture coordinates.
+func (p VertexObject) uvArray() []float32 {
+	if len(p.UVs) == 0 || len(p.UVs) != len(p.Vertices) {
+		return nil
+	}
+
+	uv := make([]float32, 0, len(p.UVs)*2)
+	for _, c := range p.UVs {
+		uv = append(uv, float32(c.X), float32(c.Y))
+	}
+
+	return uv
+}
+
 // Translation of the VertexObject by x, y pixels in the respective directions.
 func (p *VertexObject) Move(x, y float64) {
 	for i, _ := range p.Vertices {
@@ -0,0 +1,122 @@
+package layergl
+
+import (
+	"log"
+	"math"
+)
+
+type GradientKind int
+
+const (
+	// Colors change along the line from Start to End.
+	LinearGradient GradientKind = iota
+
+	// Colors change with the distance from Start, reaching the last stop at
+	// the distance of End.
+	RadialGradient
+
+	// Colors change with the angle around Start, beginning in the direction
+	// of End and going counter-clockwise.
+	ConicGradient
+)
+
+// Maximum number of color stops supported by the gradient shaders.
+const maxGradientStops = 16
+
+// Color at the Offset (0 to 1) of a gradient.
+type ColorStop struct {
+	Offset float64
+	Color  Color
+}
+
+// Gradient fill. Start and End are in the same coordinates as the filled
+// geometry, so the gradient follows its transformation. Stops must be sorted
+// by offset. Linear and radial gradients with End equal to Start are filled
+// with the color of the last stop.
+type Gradient struct {
+	Kind       GradientKind
+	Start, End Point
+	Stops      []ColorStop
+}
+
+// Returns color of the gradient at the position t, from 0 to 1.
+func (g Gradient) At(t float64) Color {
+	if len(g.Stops) == 0 {
+		return Color{}
+	}
+
+	t = math.Max(0, math.Min(1, t))
+
+	c := g.Stops[0].Color
+	for i := 1; i < len(g.Stops); i++ {
+		w := math.Max(g.Stops[i].Offset-g.Stops[i-1].Offset, 1e-6)
+		k := math.Max(0, math.Min(1, (t-g.Stops[i-1].Offset)/w))
+		c = lerpColor(c, g.Stops[i].Color, k)
+	}
+
+	return c
+}
+
+// Returns position of the point in the gradient, from 0 to 1.
+func (g Gradient) position(p Point) float64 {
+	dx, dy := g.End.X-g.Start.X, g.End.Y-g.Start.Y
+
+	if g.Kind != ConicGradient && dx == 0 && dy == 0 {
+		// Degenerate gradient, filled with the last stop.
+		return 1
+	}
+
+	var t float64
+	switch g.Kind {
+	case LinearGradient:
+		t = ((p.X-g.Start.X)*dx + (p.Y-g.Start.Y)*dy) / (dx*dx + dy*dy)
+	case RadialGradient:
+		t = Distance(p, g.Start) / math.Hypot(dx, dy)
+	case ConicGradient:
+		a := math.Atan2(p.Y-g.Start.Y, p.X-g.Start.X) - math.Atan2(dy, dx)
+		t = a / (2 * math.Pi)
+		t -= math.Floor(t)
+	}
+
+	return math.Max(0, math.Min(1, t))
+}
+
+// Returns color of the gradient at the point.
+func (g Gradient) ColorAt(p Point) Color {
+	return g.At(g.position(p))
+}
+
+func lerpColor(a, b Color, t float64) Color {
+	return Color{
+		a.R + (b.R-a.R)*t,
+		a.G + (b.G-a.G)*t,
+		a.B + (b.B-a.B)*t,
+		a.A + (b.A-a.A)*t,
+	}
+}
+
+// Sets uniforms of the gradient shader.
+func (g Gradient) apply(s *Shader) {
+	stops := g.Stops
+	if len(stops) > maxGradientStops {
+		log.Printf("Gradient: only %d color stops are supported.", maxGradientStops)
+		stops = stops[:maxGradientStops]
+	}
+	if len(stops) == 0 {
+		stops = []ColorStop{{0, Color{}}}
+	}
+
+	offsets := make([]float32, 0, len(stops))
+	colors := make([]float32, 0, len(stops)*4)
+	for _, stop := range stops {
+		offsets = append(offsets, float32(stop.Offset))
+		colors = append(colors, float32(stop.Color.R), float32(stop.Color.G), float32(stop.Color.B), float32(stop.Color.A))
+	}
+
+	s.SetInt("kind", int32(g.Kind))
+	s.SetFloat("start", float32(g.Start.X), float32(g.Start.Y))
+	s.SetFloat("end", float32(g.End.X), float32(g.End.Y))
+	s.SetInt("stopCount", int32(len(stops)))
+	s.SetFloat("offsets", offsets...)
+	s.SetFloat("colors", colors...)
+}
@@ -0,0 +1,96 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Instance is one copy of a mesh drawn by DrawInstanced.
+type Instance struct {
+	Transform Transform
+
+	// Multiplied with the colors of the mesh.
+	Color Color
+}
+
+const (
+	// Floats per instance: two rows of the transform and the color.
+	instanceFloats = 10
+
+	instanceLocation = 3
+)
+
+// Per-instance attributes shared by all meshes, refilled on every draw.
+var instanceBuffer *vertexBuffer
+
+func newInstanceBuffer() *vertexBuffer {
+	var vbo uint32
+	gl.GenBuffers(1, &vbo)
+	state.bindArrayBuffer(vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, 64*instanceFloats*t32Bytes, nil, gl.DYNAMIC_DRAW)
+
+	v := &vertexBuffer{vbo: vbo, vboSize: 64 * instanceFloats}
+	v.res = trackResource("instance buffer", "", func() { state.deleteBuffer(vbo) })
+	return v
+}
+
+// Points the instance attributes of the bound vertex array to the instance
+// buffer.
+func bindInstanceAttributes() {
+	state.bindArrayBuffer(instanceBuffer.vbo)
+
+	stride := int32(instanceFloats * t32Bytes)
+	sizes := []int32{3, 3, 4}
+	offset := 0
+	for i, size := range sizes {
+		location := uint32(instanceLocation + i)
+		gl.EnableVertexAttribArray(location)
+		gl.VertexAttribPointer(location, size, gl.FLOAT, false, stride, gl.PtrOffset(offset))
+		gl.VertexAttribDivisor(location, 1)
+		offset += int(size) * t32Bytes
+	}
+}
+
+func loadInstances(instances []Instance) {
+	data := make([]float32, 0, len(instances)*instanceFloats)
+	for _, in := range instances {
+		t, c := in.Transform, in.Color
+		data = append(data,
+			float32(t.A), float32(t.C), float32(t.E),
+			float32(t.B), float32(t.D), float32(t.F),
+			float32(c.R), float32(c.G), float32(c.B), float32(c.A))
+	}
+
+	state.bindArrayBuffer(instanceBuffer.vbo)
+	uploadBuffer(gl.ARRAY_BUFFER, &instanceBuffer.vboSize, len(data), data)
+}
+
+// Draws a copy of the mesh for every instance in a single draw call. Instance
+// transforms are applied before the transform of the mesh.
+func DrawInstanced(m *Mesh, instances []Instance) {
+	if len(instances) == 0 {
+		return
+	}
+
+	loadInstances(instances)
+	instanceShader.SetColor("color", Color{1, 1, 1, 1})
+
+	PushTransform(m.Transform)
+	instanceShader.drawInstanced(m.buffer, len(instances))
+	PopTransform()
+}
+
+// Draws a textured copy of the mesh for every instance in a single draw call,
+// see DrawMeshTexture. Instance colors tint the texture.
+func DrawInstancedTexture(m *Mesh, tex *Texture, instances []Instance) {
+	if len(instances) == 0 {
+		return
+	}
+
+	loadInstances(instances)
+	instanceTextureShader.SetFloat("region", tex.regionUniform()...)
+
+	tex.bind()
+	PushTransform(m.Transform)
+	instanceTextureShader.drawInstanced(m.buffer, len(instances))
+	PopTransform()
+}
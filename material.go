@@ -0,0 +1,99 @@
+package layergl
+
+import (
+	"log"
+)
+
+// Material is a Shader together with the uniform values it is drawn with.
+// Values are uploaded every time the material is used, so a single Shader
+// can be shared between several materials.
+type Material struct {
+	Shader *Shader
+
+	// Blend mode for draws with this material. The zero value keeps the mode
+	// set by SetBlendMode.
+	Blend BlendMode
+
+	uniforms map[string]func() error
+	textures map[string]materialTexture
+	reported map[string]bool
+}
+
+type materialTexture struct {
+	texture *Texture
+	unit    uint32
+}
+
+// Creates new Material using the shader.
+func NewMaterial(shader *Shader) *Material {
+	return &Material{
+		Shader:   shader,
+		uniforms: make(map[string]func() error),
+		textures: make(map[string]materialTexture),
+		reported: make(map[string]bool),
+	}
+}
+
+func (m *Material) SetFloat(name string, val ...float32) {
+	m.set(name, func() error { return m.Shader.SetFloat(name, val...) })
+}
+
+func (m *Material) SetInt(name string, val ...int32) {
+	m.set(name, func() error { return m.Shader.SetInt(name, val...) })
+}
+
+func (m *Material) SetBool(name string, val ...bool) {
+	m.set(name, func() error { return m.Shader.SetBool(name, val...) })
+}
+
+func (m *Material) SetMatrix(name string, val []float32) {
+	m.set(name, func() error { return m.Shader.SetMatrix(name, val) })
+}
+
+func (m *Material) SetColor(name string, color Color) {
+	m.set(name, func() error { return m.Shader.SetColor(name, color) })
+}
+
+// Binds texture to the sampler uniform. Texture unit 0 is reserved for the
+// texture passed to DrawTextureMaterial, additional textures use units 1 and up.
+func (m *Material) SetTexture(name string, texture *Texture) {
+	t, ok := m.textures[name]
+	if !ok {
+		t.unit = uint32(len(m.textures) + 1)
+	}
+	t.texture = texture
+	m.textures[name] = t
+
+	m.set(name, func() error { return m.Shader.SetInt(name, int32(t.unit)) })
+}
+
+func (m *Material) set(name string, set func() error) {
+	m.uniforms[name] = set
+	delete(m.reported, name)
+}
+
+// Binds the shader, its textures and uploads all uniform values. Errors are
+// logged once per uniform until its value is changed.
+func (m *Material) apply() {
+	m.Shader.use()
+
+	if m.Blend != (BlendMode{}) {
+		state.setBlend(m.Blend)
+	}
+
+	for _, t := range m.textures {
+		t.texture.bindUnit(t.unit)
+	}
+
+	for name, set := range m.uniforms {
+		if err := set(); err != nil && m.Shader.HasUniform(name) && !m.reported[name] {
+			m.reported[name] = true
+			log.Println(err)
+		}
+	}
+}
+
+// Restores state changed by apply.
+func (m *Material) restore() {
+	state.setBlend(blendMode)
+}
@@ -0,0 +1,58 @@
+package layergl
+
+// Mesh is geometry uploaded once to its own GPU buffers. Unlike DrawVertexObject,
+// drawing a mesh does not convert or upload anything, so it suits large static
+// geometry such as maps. Changes of the source VertexObject take effect only
+// after Update.
+type Mesh struct {
+	// Transformation applied on top of the current transform when drawn.
+	// Identity by default.
+	Transform Transform
+
+	buffer *vertexBuffer
+	bounds Rect
+}
+
+// Uploads the vertices, indices and the optional colors and texture
+// coordinates of the VertexObject into new Mesh.
+func NewMesh(v *VertexObject) *Mesh {
+	m := &Mesh{Transform: Identity(), buffer: newStaticBuffer("")}
+	m.Update(v)
+	return m
+}
+
+// Replaces the geometry of the mesh with the VertexObject.
+func (m *Mesh) Update(v *VertexObject) {
+	vertices, elements := v.vertexArray()
+	m.buffer.loadStatic(vertices, v.uvArray(), v.colorArray(), elements)
+	m.bounds = v.Bounds()
+}
+
+// Returns bounds of the geometry, not including the transform.
+func (m *Mesh) Bounds() Rect {
+	return m.bounds
+}
+
+// Deletes the GPU buffers. The mesh must not be used afterwards.
+func (m *Mesh) Delete() {
+	m.buffer.delete()
+}
+
+// Draws the mesh filled with the color. If it has per-vertex colors, they
+// are multiplied by the color.
+func DrawMesh(m *Mesh, color Color) {
+	PushTransform(m.Transform)
+	polygonShader.drawColor(m.buffer, color)
+	PopTransform()
+}
+
+// Draws the mesh textured using its texture coordinates, which are relative
+// to the region of the texture if set.
+func DrawMeshTexture(m *Mesh, tex *Texture) {
+	textureShader.SetFloat("region", tex.regionUniform()...)
+	textureShader.SetBool("wrap", false)
+
+	PushTransform(m.Transform)
+	textureShader.drawTexture(m.buffer, tex)
+	PopTransform()
+}
@@ -0,0 +1,215 @@
+package layergl
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+)
+
+// Insets are sizes of the borders of a rectangle in pixels.
+type Insets struct {
+	Left, Top, Right, Bottom float64
+}
+
+// NinePatch is a texture with borders that keep their size when the texture
+// is scaled, as read from Android .9.png images.
+type NinePatch struct {
+	Texture *Texture
+
+	// Borders in pixels of the texture.
+	Insets Insets
+
+	// Space around the content, from the bottom and right markers of the
+	// .9.png image, or equal to Insets if there are none.
+	Padding Insets
+
+	// Repeat the center and the edges instead of stretching them.
+	Tiled bool
+}
+
+// Decodes .9.png image: the image with one pixel border marking the stretched
+// area with black pixels on the top and left and the content area on the
+// bottom and right. Only the first and the last marker pixel are used, so
+// each side has a single stretched area.
+func NewNinePatchFromReader(r io.Reader, options ...TextureOptions) (*NinePatch, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, insets, padding, err := ninePatchLayout(img)
+	if err != nil {
+		return nil, fmt.Errorf("NewNinePatchFromReader: %v", err)
+	}
+
+	sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	})
+	if !ok {
+		return nil, fmt.Errorf("NewNinePatchFromReader: unsupported image type %T", img)
+	}
+
+	tex, err := NewTextureFromImage(sub.SubImage(inner), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NinePatch{Texture: tex, Insets: insets, Padding: padding}, nil
+}
+
+// Reads the markers of .9.png image. Returns the image without the marker
+// border, the insets of the stretched area and the padding of the content.
+func ninePatchLayout(img image.Image) (inner image.Rectangle, insets, padding Insets, err error) {
+	b := img.Bounds()
+	if b.Dx() < 3 || b.Dy() < 3 {
+		return inner, insets, padding, fmt.Errorf("%dx%d image is too small", b.Dx(), b.Dy())
+	}
+
+	inner = image.Rect(b.Min.X+1, b.Min.Y+1, b.Max.X-1, b.Max.Y-1)
+	w, h := inner.Dx(), inner.Dy()
+
+	left, right, ok := ninePatchMarkers(img, inner.Min.X, b.Min.Y, 1, 0, w)
+	if !ok {
+		return inner, insets, padding, fmt.Errorf("missing horizontal stretch markers")
+	}
+	top, bottom, ok := ninePatchMarkers(img, b.Min.X, inner.Min.Y, 0, 1, h)
+	if !ok {
+		return inner, insets, padding, fmt.Errorf("missing vertical stretch markers")
+	}
+	insets = Insets{float64(left), float64(top), float64(w - right), float64(h - bottom)}
+
+	padding = insets
+	if l, r, ok := ninePatchMarkers(img, inner.Min.X, b.Max.Y-1, 1, 0, w); ok {
+		padding.Left, padding.Right = float64(l), float64(w-r)
+	}
+	if t, b, ok := ninePatchMarkers(img, b.Max.X-1, inner.Min.Y, 0, 1, h); ok {
+		padding.Top, padding.Bottom = float64(t), float64(h-b)
+	}
+
+	return inner, insets, padding, nil
+}
+
+// Scans n border pixels from x, y in the direction dx, dy and returns the
+// range of the black markers, relative to the start.
+func ninePatchMarkers(img image.Image, x, y, dx, dy, n int) (start, end int, ok bool) {
+	start = -1
+	for i := 0; i < n; i++ {
+		r, g, b, a := img.At(x+i*dx, y+i*dy).RGBA()
+		if a > 0x8000 && r < 0x8000 && g < 0x8000 && b < 0x8000 {
+			if start < 0 {
+				start = i
+			}
+			end = i + 1
+		}
+	}
+
+	return start, end, start >= 0
+}
+
+// Draws the nine-patch stretched or tiled to the rectangle.
+func (n *NinePatch) Draw(dest Rect) {
+	drawNinePatch(n.Texture, dest, n.Insets, n.Tiled)
+}
+
+// Draws the texture, or its region, scaled to the rectangle without scaling
+// the borders: corners keep their size, edges are stretched along one axis
+// and the center along both. Borders are shrunk if the rectangle is smaller
+// than them.
+func DrawNinePatch(tex *Texture, dest Rect, insets Insets) {
+	drawNinePatch(tex, dest, insets, false)
+}
+
+// Like DrawNinePatch, but repeats the edges and the center instead of
+// stretching them.
+func DrawNinePatchTiled(tex *Texture, dest Rect, insets Insets) {
+	drawNinePatch(tex, dest, insets, true)
+}
+
+// Part of a row or column of the nine-patch: destination range and the
+// source range in texture coordinates.
+type ninePatchSpan struct {
+	d1, d2 float64
+	s1, s2 float64
+}
+
+func drawNinePatch(tex *Texture, dest Rect, insets Insets, tiled bool) {
+	bounds := tex.pixelBounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	if w == 0 || h == 0 {
+		return
+	}
+
+	// Texture coordinates have the origin at the bottom, so the bottom inset
+	// comes first vertically.
+	columns := ninePatchSpans(dest.X1, dest.X2, insets.Left, insets.Right, w, tiled)
+	rows := ninePatchSpans(dest.Y1, dest.Y2, insets.Bottom, insets.Top, h, tiled)
+
+	var vertices []float32
+	var uvs []float32
+	var elements []uint32
+	for _, row := range rows {
+		for _, col := range columns {
+			i := uint32(len(vertices) / 2)
+			vertices = append(vertices,
+				float32(col.d1), float32(row.d1),
+				float32(col.d1), float32(row.d2),
+				float32(col.d2), float32(row.d1),
+				float32(col.d2), float32(row.d2))
+			uvs = append(uvs,
+				float32(col.s1), float32(row.s1),
+				float32(col.s1), float32(row.s2),
+				float32(col.s2), float32(row.s1),
+				float32(col.s2), float32(row.s2))
+			elements = append(elements, i, i+1, i+2, i+1, i+2, i+3)
+		}
+	}
+
+	vertBuffer.loadVertexArray(vertices, elements)
+	vertBuffer.loadUVs(uvs)
+	textureShader.SetFloat("region", tex.regionUniform()...)
+	textureShader.SetBool("wrap", false)
+	textureShader.drawTexture(vertBuffer, tex)
+}
+
+// Splits the range d1 to d2 into the start border, the middle and the end
+// border of a texture of the given size. Tiled middles are split into
+// repetitions of the middle of the texture.
+func ninePatchSpans(d1, d2, start, end, size float64, tiled bool) []ninePatchSpan {
+	length := d2 - d1
+	if length <= 0 {
+		return nil
+	}
+
+	// Shrink the borders proportionally if they do not fit.
+	ds, de := start, end
+	if start+end > length {
+		k := length / (start + end)
+		ds, de = start*k, end*k
+	}
+
+	var spans []ninePatchSpan
+	if ds > 0 {
+		spans = append(spans, ninePatchSpan{d1, d1 + ds, 0, start / size})
+	}
+
+	m1, m2 := d1+ds, d2-de
+	s1, s2 := start/size, (size-end)/size
+	middle := size - start - end
+	if m2 > m1 && middle > 0 {
+		if !tiled {
+			spans = append(spans, ninePatchSpan{m1, m2, s1, s2})
+		} else {
+			for d := m1; d < m2; d += middle {
+				k := math.Min(1, (m2-d)/middle)
+				spans = append(spans, ninePatchSpan{d, d + middle*k, s1, s1 + (s2-s1)*k})
+			}
+		}
+	}
+
+	if de > 0 {
+		spans = append(spans, ninePatchSpan{d2 - de, d2, (size - end) / size, 1})
+	}
+
+	return spans
+}
@@ -0,0 +1,180 @@
+package layergl
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// Builds .9.png image with a 10x8 content area. Markers are given as ranges
+// of the inner pixels; empty ranges leave the side without markers.
+func testNinePatchImage(top, left, bottom, right [2]int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 12, 10))
+	for y := 1; y < 9; y++ {
+		for x := 1; x < 11; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	black := color.Black
+	for x := top[0]; x < top[1]; x++ {
+		img.Set(x+1, 0, black)
+	}
+	for y := left[0]; y < left[1]; y++ {
+		img.Set(0, y+1, black)
+	}
+	for x := bottom[0]; x < bottom[1]; x++ {
+		img.Set(x+1, 9, black)
+	}
+	for y := right[0]; y < right[1]; y++ {
+		img.Set(11, y+1, black)
+	}
+	return img
+}
+
+func TestNinePatchLayout(t *testing.T) {
+	tests := []struct {
+		name                     string
+		top, left, bottom, right [2]int
+		insets, padding          Insets
+	}{
+		{
+			name: "content markers",
+			top:  [2]int{3, 7}, left: [2]int{2, 5},
+			bottom: [2]int{1, 9}, right: [2]int{1, 6},
+			insets:  Insets{3, 2, 3, 3},
+			padding: Insets{1, 1, 1, 2},
+		},
+		{
+			name: "no content markers",
+			top:  [2]int{3, 7}, left: [2]int{2, 5},
+			insets:  Insets{3, 2, 3, 3},
+			padding: Insets{3, 2, 3, 3},
+		},
+		{
+			name: "horizontal content markers only",
+			top:  [2]int{3, 7}, left: [2]int{2, 5},
+			bottom:  [2]int{4, 6},
+			insets:  Insets{3, 2, 3, 3},
+			padding: Insets{4, 2, 4, 3},
+		},
+		{
+			// Only the first and the last marker pixels count.
+			name: "whole side",
+			top:  [2]int{0, 10}, left: [2]int{0, 8},
+			insets:  Insets{},
+			padding: Insets{},
+		},
+	}
+
+	for _, test := range tests {
+		img := testNinePatchImage(test.top, test.left, test.bottom, test.right)
+		inner, insets, padding, err := ninePatchLayout(img)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if inner != image.Rect(1, 1, 11, 9) {
+			t.Errorf("%s: inner %v", test.name, inner)
+		}
+		if insets != test.insets || padding != test.padding {
+			t.Errorf("%s: insets %v, padding %v, want %v, %v", test.name, insets, padding, test.insets, test.padding)
+		}
+	}
+}
+
+func TestNinePatchLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"no markers", testNinePatchImage([2]int{}, [2]int{}, [2]int{}, [2]int{})},
+		{"no vertical markers", testNinePatchImage([2]int{3, 7}, [2]int{}, [2]int{1, 9}, [2]int{1, 6})},
+		{"no horizontal markers", testNinePatchImage([2]int{}, [2]int{2, 5}, [2]int{1, 9}, [2]int{1, 6})},
+		{"too small", image.NewNRGBA(image.Rect(0, 0, 2, 10))},
+	}
+	for _, test := range tests {
+		if _, _, _, err := ninePatchLayout(test.img); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
+
+func TestNinePatchSpans(t *testing.T) {
+	tests := []struct {
+		name       string
+		d1, d2     float64
+		start, end float64
+		tiled      bool
+		want       []ninePatchSpan
+	}{
+		{
+			name: "stretched",
+			d1:   10, d2: 110, start: 10, end: 20,
+			want: []ninePatchSpan{
+				{10, 20, 0, 0.25},
+				{20, 90, 0.25, 0.5},
+				{90, 110, 0.5, 1},
+			},
+		},
+		{
+			// The borders keep their proportion and the middle disappears.
+			name: "shrunk borders",
+			d1:   0, d2: 15, start: 10, end: 20,
+			want: []ninePatchSpan{
+				{0, 5, 0, 0.25},
+				{5, 15, 0.5, 1},
+			},
+		},
+		{
+			name: "exact borders",
+			d1:   0, d2: 30, start: 10, end: 20,
+			want: []ninePatchSpan{
+				{0, 10, 0, 0.25},
+				{10, 30, 0.5, 1},
+			},
+		},
+		{
+			// The middle of the texture is 10 pixels, the last copy is cut
+			// in half.
+			name: "tiled",
+			d1:   0, d2: 55, start: 10, end: 20, tiled: true,
+			want: []ninePatchSpan{
+				{0, 10, 0, 0.25},
+				{10, 20, 0.25, 0.5},
+				{20, 30, 0.25, 0.5},
+				{30, 35, 0.25, 0.375},
+				{35, 55, 0.5, 1},
+			},
+		},
+		{
+			name: "no borders",
+			d1:   0, d2: 100, tiled: true,
+			want: []ninePatchSpan{
+				{0, 40, 0, 1},
+				{40, 80, 0, 1},
+				{80, 100, 0, 0.5},
+			},
+		},
+		{
+			name: "empty",
+			d1:   10, d2: 10, start: 10, end: 20,
+		},
+	}
+
+	for _, test := range tests {
+		got := ninePatchSpans(test.d1, test.d2, test.start, test.end, 40, test.tiled)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: spans %v, want %v", test.name, got, test.want)
+			continue
+		}
+		for i, s := range got {
+			w := test.want[i]
+			if math.Abs(s.d1-w.d1) > 1e-9 || math.Abs(s.d2-w.d2) > 1e-9 || math.Abs(s.s1-w.s1) > 1e-9 || math.Abs(s.s2-w.s2) > 1e-9 {
+				t.Errorf("%s: spans %v, want %v", test.name, got, test.want)
+				break
+			}
+		}
+	}
+}
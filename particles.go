@@ -0,0 +1,263 @@
+package layergl
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Range is an interval values are sampled from uniformly.
+type Range struct {
+	Min, Max float64
+}
+
+func (r Range) sample(rng *rand.Rand) float64 {
+	if r.Max <= r.Min {
+		return r.Min
+	}
+	return r.Min + rng.Float64()*(r.Max-r.Min)
+}
+
+// CurveKey is a value of a Curve at the time T, from 0 to 1.
+type CurveKey struct {
+	T, Value float64
+}
+
+// Curve is a piecewise linear function of time given by keys in increasing T.
+type Curve []CurveKey
+
+// Returns value of the curve at t. Values before the first and after the last
+// key are constant, empty curve is 1.
+func (c Curve) At(t float64) float64 {
+	if len(c) == 0 {
+		return 1
+	}
+	if t <= c[0].T {
+		return c[0].Value
+	}
+
+	for i := 1; i < len(c); i++ {
+		if t < c[i].T {
+			k := (t - c[i-1].T) / (c[i].T - c[i-1].T)
+			return c[i-1].Value + (c[i].Value-c[i-1].Value)*k
+		}
+	}
+
+	return c[len(c)-1].Value
+}
+
+// Particle is a single particle of ParticleEmitter. Times are in seconds.
+type Particle struct {
+	Position, Velocity Point
+
+	// Initial size and rotation, before the lifetime curves are applied.
+	Size            float64
+	Rotation        float64
+	AngularVelocity float64
+
+	Age, Lifetime float64
+}
+
+// ParticleEmitter spawns and simulates particles. Simulation does not use the
+// GPU and is deterministic for a given seed and sequence of updates. Emitters
+// not created by NewParticleEmitter use seed 0.
+type ParticleEmitter struct {
+	Position Point
+
+	// Particles spawned per second and the limit of live particles.
+	Rate         float64
+	MaxParticles int
+
+	// Lifetime in seconds.
+	Lifetime Range
+
+	// Direction of the initial velocity in radians and its magnitude in
+	// pixels per second.
+	Angle, Speed Range
+
+	// Acceleration in pixels per second squared.
+	Gravity Point
+
+	// Rate of the exponential decay of the velocity: over t seconds it is
+	// multiplied by e^(-Drag·t), regardless of the frame rate. Drag 1 loses
+	// 63% of the velocity per second.
+	Drag float64
+
+	// Initial size in pixels, multiplied by SizeOverLife.
+	Size         Range
+	SizeOverLife Curve
+
+	// Initial rotation in radians and its change per second. RotationOverLife
+	// is added to the rotation, an empty curve adds nothing.
+	Rotation         Range
+	AngularVelocity  Range
+	RotationOverLife Curve
+
+	// Color of the particles, multiplied by ColorOverLife if it has stops.
+	Color         Color
+	ColorOverLife Gradient
+
+	// Drawn shape, centered at the origin with size 1. Defaults to a square.
+	Shape *VertexObject
+
+	// Texture drawn on the shape, using its texture coordinates. Only the
+	// region of the texture is used.
+	Texture *Texture
+
+	// Blend mode of the particles. The zero value keeps the mode set by
+	// SetBlendMode.
+	Blend BlendMode
+
+	particles []Particle
+	rng       *rand.Rand
+	pending   float64
+
+	mesh      *Mesh
+	meshShape *VertexObject
+}
+
+// Creates emitter with no particles, white square particles of size 1 and
+// alpha blending.
+func NewParticleEmitter(seed int64) *ParticleEmitter {
+	return &ParticleEmitter{
+		MaxParticles: 1000,
+		Lifetime:     Range{1, 1},
+		Size:         Range{1, 1},
+		Color:        Color{1, 1, 1, 1},
+		Blend:        BlendAlpha,
+		rng:          rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Returns the live particles. The slice is reused by Update.
+func (e *ParticleEmitter) Particles() []Particle {
+	return e.particles
+}
+
+// Spawns n particles at once, up to MaxParticles.
+func (e *ParticleEmitter) Burst(n int) {
+	for i := 0; i < n && len(e.particles) < e.MaxParticles; i++ {
+		e.spawn()
+	}
+}
+
+func (e *ParticleEmitter) spawn() {
+	if e.rng == nil {
+		e.rng = rand.New(rand.NewSource(0))
+	}
+
+	angle := e.Angle.sample(e.rng)
+	speed := e.Speed.sample(e.rng)
+	sin, cos := math.Sincos(angle)
+
+	e.particles = append(e.particles, Particle{
+		Position:        e.Position,
+		Velocity:        Point{cos * speed, sin * speed},
+		Size:            e.Size.sample(e.rng),
+		Rotation:        e.Rotation.sample(e.rng),
+		AngularVelocity: e.AngularVelocity.sample(e.rng),
+		Lifetime:        e.Lifetime.sample(e.rng),
+	})
+}
+
+// Advances the simulation: moves particles, removes expired ones and spawns
+// new ones according to Rate.
+func (e *ParticleEmitter) Update(dt time.Duration) {
+	seconds := dt.Seconds()
+	drag := math.Exp(-e.Drag * seconds)
+
+	live := e.particles[:0]
+	for _, p := range e.particles {
+		p.Age += seconds
+		if p.Age >= p.Lifetime {
+			continue
+		}
+
+		p.Velocity.X = (p.Velocity.X + e.Gravity.X*seconds) * drag
+		p.Velocity.Y = (p.Velocity.Y + e.Gravity.Y*seconds) * drag
+		p.Position.X += p.Velocity.X * seconds
+		p.Position.Y += p.Velocity.Y * seconds
+		p.Rotation += p.AngularVelocity * seconds
+		live = append(live, p)
+	}
+	e.particles = live
+
+	e.pending += e.Rate * seconds
+	n := int(e.pending)
+	e.pending -= float64(n)
+	e.Burst(n)
+}
+
+// Returns the instance drawing the particle in its current state.
+func (e *ParticleEmitter) instance(p Particle) Instance {
+	t := p.Age / p.Lifetime
+	size := p.Size * e.SizeOverLife.At(t)
+	rotation := p.Rotation
+	if len(e.RotationOverLife) != 0 {
+		rotation += e.RotationOverLife.At(t)
+	}
+
+	color := e.Color
+	if len(e.ColorOverLife.Stops) != 0 {
+		c := e.ColorOverLife.At(t)
+		color = Color{color.R * c.R, color.G * c.G, color.B * c.B, color.A * c.A}
+	}
+
+	return Instance{
+		Transform: Translation(p.Position.X, p.Position.Y).Mul(Rotation(rotation)).Mul(Scaling(size, size)),
+		Color:     color,
+	}
+}
+
+// Returns the instances of all live particles.
+func (e *ParticleEmitter) Instances() []Instance {
+	instances := make([]Instance, len(e.particles))
+	for i, p := range e.particles {
+		instances[i] = e.instance(p)
+	}
+	return instances
+}
+
+// Deletes the GPU mesh of the particles. The emitter may be drawn again.
+func (e *ParticleEmitter) Delete() {
+	if e.mesh != nil {
+		e.mesh.Delete()
+		e.mesh, e.meshShape = nil, nil
+	}
+}
+
+var particleQuad = &VertexObject{
+	Vertices: []Point{{-0.5, -0.5}, {-0.5, 0.5}, {0.5, -0.5}, {0.5, 0.5}},
+	Indices:  []int{0, 1, 2, 1, 2, 3},
+	UVs:      []Point{{0, 0}, {0, 1}, {1, 0}, {1, 1}},
+}
+
+// Draws all particles of the emitter in a single instanced draw call.
+func DrawParticles(e *ParticleEmitter) {
+	if len(e.particles) == 0 {
+		return
+	}
+
+	shape := e.Shape
+	if shape == nil {
+		shape = particleQuad
+	}
+	if e.mesh == nil {
+		e.mesh = NewMesh(shape)
+	} else if e.meshShape != shape {
+		e.mesh.Update(shape)
+	}
+	e.meshShape = shape
+
+	if e.Blend != (BlendMode{}) {
+		previous := CurrentBlendMode()
+		SetBlendMode(e.Blend)
+		defer SetBlendMode(previous)
+	}
+
+	if e.Texture != nil {
+		DrawInstancedTexture(e.mesh, e.Texture, e.Instances())
+	} else {
+		DrawInstanced(e.mesh, e.Instances())
+	}
+}
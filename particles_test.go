@@ -0,0 +1,131 @@
+package layergl
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Time step exactly representable in seconds, so that ages and positions
+// can be compared exactly.
+const particleStep = 125 * time.Millisecond
+
+func updateEmitter(e *ParticleEmitter, n int) {
+	for i := 0; i < n; i++ {
+		e.Update(particleStep)
+	}
+}
+
+func TestParticleEmitterSteadyState(t *testing.T) {
+	e := NewParticleEmitter(1)
+	e.Rate = 8
+	e.Lifetime = Range{1, 1}
+	e.Speed = Range{100, 100}
+
+	// One particle is spawned per update and lives for 8 updates.
+	updateEmitter(e, 20)
+
+	particles := e.Particles()
+	if len(particles) != 8 {
+		t.Fatalf("%d particles, want 8", len(particles))
+	}
+	for i, p := range particles {
+		age := float64(len(particles)-1-i) * particleStep.Seconds()
+		if p.Age != age || p.Lifetime != 1 {
+			t.Errorf("particle %d: age %v, lifetime %v, want %v, 1", i, p.Age, p.Lifetime, age)
+		}
+		if want := (Point{age * 100, 0}); p.Position != want {
+			t.Errorf("particle %d: position %v, want %v", i, p.Position, want)
+		}
+	}
+}
+
+func TestParticleEmitterGravity(t *testing.T) {
+	e := NewParticleEmitter(1)
+	e.Lifetime = Range{10, 10}
+	e.Gravity = Point{0, -10}
+	e.Burst(1)
+
+	updateEmitter(e, 4)
+
+	// Velocity gains -1.25 per update and moves the particle by an eighth
+	// of it: -1.25 * (1+2+3+4) / 8.
+	p := e.Particles()[0]
+	if want := (Point{0, -5}); p.Velocity != want {
+		t.Errorf("velocity %v, want %v", p.Velocity, want)
+	}
+	if want := (Point{0, -1.5625}); p.Position != want {
+		t.Errorf("position %v, want %v", p.Position, want)
+	}
+}
+
+func TestParticleEmitterDeterministic(t *testing.T) {
+	newEmitter := func(seed int64) *ParticleEmitter {
+		e := NewParticleEmitter(seed)
+		e.Rate = 100
+		e.Lifetime = Range{0.5, 2}
+		e.Angle = Range{0, 6}
+		e.Speed = Range{10, 50}
+		e.Size = Range{1, 4}
+		e.AngularVelocity = Range{-1, 1}
+		e.Drag = 0.5
+		e.Gravity = Point{0, -20}
+		return e
+	}
+
+	a, b, c := newEmitter(42), newEmitter(42), newEmitter(43)
+	for _, e := range []*ParticleEmitter{a, b, c} {
+		updateEmitter(e, 30)
+	}
+
+	if len(a.Particles()) == 0 {
+		t.Fatal("no particles spawned")
+	}
+	if !reflect.DeepEqual(a.Particles(), b.Particles()) {
+		t.Error("emitters with the same seed differ")
+	}
+	if reflect.DeepEqual(a.Particles(), c.Particles()) {
+		t.Error("emitters with different seeds are equal")
+	}
+}
+
+func TestParticleEmitterMaxParticles(t *testing.T) {
+	e := NewParticleEmitter(1)
+	e.Rate = 1000
+	e.MaxParticles = 10
+	e.Lifetime = Range{10, 10}
+
+	updateEmitter(e, 5)
+	if n := len(e.Particles()); n != 10 {
+		t.Errorf("%d particles, want 10", n)
+	}
+}
+
+func TestParticleEmitterLiteral(t *testing.T) {
+	e := &ParticleEmitter{Rate: 8, MaxParticles: 100, Lifetime: Range{1, 1}, Angle: Range{0, 1}, Size: Range{1, 1}}
+	updateEmitter(e, 4)
+
+	want := NewParticleEmitter(0)
+	want.Rate, want.Angle = e.Rate, e.Angle
+	updateEmitter(want, 4)
+
+	if !reflect.DeepEqual(e.Particles(), want.Particles()) {
+		t.Error("literal emitter differs from emitter created with seed 0")
+	}
+}
+
+func TestParticleEmitterDrag(t *testing.T) {
+	e := NewParticleEmitter(1)
+	e.Lifetime = Range{10, 10}
+	e.Speed = Range{100, 100}
+	e.Drag = 1
+	e.Burst(1)
+
+	// The decay does not depend on the number of updates.
+	updateEmitter(e, 8)
+	want := 100 * math.Exp(-1)
+	if v := e.Particles()[0].Velocity.X; math.Abs(v-want) > 1e-9 {
+		t.Errorf("velocity %v after a second, want %v", v, want)
+	}
+}
@@ -3,40 +3,92 @@ package layergl
 import (
 	"fmt"
 	"github.com/go-gl/gl/v3.3-core/gl"
+	"log"
 )
 
 var (
-	polygonShader shader
-	circleShader  shader
-	textureShader shader
-	fontShader    shader
-	vertBuffer    *vertexBuffer
+	polygonShader         *Shader
+	circleShader          *Shader
+	textureShader         *Shader
+	fontShader            *Shader
+	gradientShader        *Shader
+	fontGradientShader    *Shader
+	instanceShader        *Shader
+	instanceTextureShader *Shader
+	vertBuffer            *vertexBuffer
+
+	screenWidth, screenHeight int
+
+	// Projection of screen pixels to clip space and its product with the
+	// current transformation.
+	screenProjection []float32
+	projectionMatrix []float32
 )
 
 func DrawTexture(d *Texture) {
 	vertBuffer.loadVertexArray(d.vertexArray())
-	vertBuffer.loadUVs([]float32{
-		0.0, 0.0,
-		0.0, 1.0,
-		1.0, 0.0,
-		1.0, 1.0,
-	})
+	vertBuffer.loadUVs(d.uvs())
+	d.applyRegion(textureShader)
 	textureShader.drawTexture(vertBuffer, d)
 }
 
+// Draws texture using the custom material instead of the built-in shader.
+// The texture is bound to unit 0 and assigned to the "tex" sampler if present.
+func DrawTextureMaterial(d *Texture, m *Material) {
+	vertBuffer.loadVertexArray(d.vertexArray())
+	vertBuffer.loadUVs(d.uvs())
+
+	m.apply()
+	if m.Shader.HasUniform("tex") {
+		m.Shader.SetInt("tex", 0)
+	}
+	d.applyRegion(m.Shader)
+	m.Shader.drawTexture(vertBuffer, d)
+	m.restore()
+}
+
 func DrawRect(rect Rect, color Color) {
 	vertBuffer.loadVertexArray(rect.vertexArray())
 	polygonShader.drawColor(vertBuffer, color)
 }
 
+// Draws VertexObject. If it has per-vertex colors, they are multiplied by
+// the color.
 func DrawVertexObject(d *VertexObject, color Color) {
 	vertBuffer.loadVertexArray(d.vertexArray())
+	vertBuffer.loadColors(d.colorArray())
 	polygonShader.drawColor(vertBuffer, color)
 }
 
+func DrawRectGradient(rect Rect, g Gradient) {
+	vertBuffer.loadVertexArray(rect.vertexArray())
+	g.apply(gradientShader)
+	gradientShader.draw(vertBuffer, gl.TRIANGLES)
+}
+
+// Fills VertexObject with the gradient. If it has per-vertex colors, they are
+// multiplied by the gradient.
+func DrawVertexObjectGradient(d *VertexObject, g Gradient) {
+	vertBuffer.loadVertexArray(d.vertexArray())
+	vertBuffer.loadColors(d.colorArray())
+	g.apply(gradientShader)
+	gradientShader.draw(vertBuffer, gl.TRIANGLES)
+}
+
+// Draws VertexObject using the custom material instead of the built-in shader.
+func DrawVertexObjectMaterial(d *VertexObject, m *Material) {
+	vertBuffer.loadVertexArray(d.vertexArray())
+	m.apply()
+	m.Shader.draw(vertBuffer, gl.TRIANGLES)
+	m.restore()
+}
+
 func DrawPoint(d Point, r float64, color Color) {
 	rect := Rect{d.X - r, d.Y - r, d.X + r, d.Y + r}
-	circleShader.setUniformVec("circle", float32(d.X), float32(d.Y), float32(r))
+
+	// The circle is computed in window coordinates by the fragment shader.
+	center := transform.Apply(d)
+	circleShader.SetFloat("circle", float32(center.X), float32(center.Y), float32(r*transform.scaleFactor()))
 	vertBuffer.loadVertexArray(rect.vertexArray())
 	circleShader.drawColor(vertBuffer, color)
 }
@@ -55,8 +107,28 @@ func DrawLines(points []Point, color Color) {
 	polygonShader.drawLines(vertBuffer, color)
 }
 
+// Clears the whole screen, regardless of the clip rectangle. Also marks the
+// beginning of a new frame.
 func Clear() {
-	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	frameBoundary()
+
+	// The scissor test limits gl.Clear as well.
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+	applyClip()
+
+	if len(masks) > 0 {
+		rebuildMasks()
+	}
+}
+
+// Work deferred until the start of the next frame.
+func frameBoundary() {
+	nextFrameStats()
+
+	for _, w := range shaderWatchers {
+		w.Poll()
+	}
 }
 
 func Init(width, height int) error {
@@ -64,11 +136,13 @@ func Init(width, height int) error {
 		return err
 	}
 
+	state.reset()
+	initTextureFeatures()
+
 	versionString := gl.GoStr(gl.GetString(gl.VERSION))
 	fmt.Println("OpenGL Version", versionString)
 
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+	SetBlendMode(BlendAlpha)
 
 	gl.Enable(gl.MULTISAMPLE)
 
@@ -80,22 +154,52 @@ func Init(width, height int) error {
 	gl.ClearColor(0, 0, 0, 1)
 
 	vertBuffer = newVertexBuffer(128)
+	instanceBuffer = newInstanceBuffer()
 
-	polygonShader = newShaderProgram(vertexVert, polygonFrag)
-	circleShader = newShaderProgram(vertexVert, circleFrag)
-	textureShader = newShaderProgram(textureVert, textureFrag)
-	fontShader = newShaderProgram(fontVert, textureFrag)
+	var err error
+	if polygonShader, err = NewShader(vertexVert, polygonFrag); err != nil {
+		return err
+	}
+	if circleShader, err = NewShader(vertexVert, circleFrag); err != nil {
+		return err
+	}
+	if textureShader, err = NewShader(textureVert, textureFrag); err != nil {
+		return err
+	}
+	if fontShader, err = NewShader(textureVert, fontFrag); err != nil {
+		return err
+	}
+	if gradientShader, err = NewShader(gradientVert, gradientFrag); err != nil {
+		return err
+	}
+	if fontGradientShader, err = NewShader(gradientVert, fontGradientFrag); err != nil {
+		return err
+	}
+	if instanceShader, err = NewShader(instanceVert, polygonFrag); err != nil {
+		return err
+	}
+	if instanceTextureShader, err = NewShader(instanceVert, instanceTextureFrag); err != nil {
+		return err
+	}
 
-	projectionMatrix := orthoProjection(0, float32(width), 0, float32(height), -1, 1)
-	polygonShader.setUniformMat("projection", projectionMatrix)
-	circleShader.setUniformMat("projection", projectionMatrix)
-	textureShader.setUniformMat("projection", projectionMatrix)
+	screenWidth, screenHeight = width, height
+	screenProjection = orthoProjection(0, float32(width), 0, float32(height), -1, 1)
+	setTransform(Identity())
 
-	textureShader.setUniformVec("tex", 0)
+	textureShader.SetInt("tex", 0)
 
 	return nil
 }
 
+// Updates the viewport and the projection after the size of the window
+// changed.
+func Resize(width, height int) {
+	screenWidth, screenHeight = width, height
+	gl.Viewport(0, 0, int32(width), int32(height))
+	screenProjection = orthoProjection(0, float32(width), 0, float32(height), -1, 1)
+	setTransform(transform)
+}
+
 func ClearColor(color Color) {
 	gl.ClearColor(float32(color.R), float32(color.G), float32(color.B), float32(color.A))
 }
@@ -111,7 +215,20 @@ func orthoProjection(left, right, bottom, top, near, far float32) []float32 {
 }
 
 func (f *Font) Printf(point Point, color Color, scale float64, fs string, argv ...interface{}) {
-	indices := []rune(fmt.Sprintf(fs, argv...))
+	fontShader.SetColor("textColor", color)
+	f.print(fontShader, point, scale, fmt.Sprintf(fs, argv...))
+}
+
+// Prints text filled with the gradient. The gradient is given in the same
+// coordinates as the point.
+func (f *Font) PrintfGradient(point Point, g Gradient, scale float64, fs string, argv ...interface{}) {
+	g.apply(fontGradientShader)
+	f.print(fontGradientShader, point, scale, fmt.Sprintf(fs, argv...))
+}
+
+// Draws glyphs of the text with the shader, starting at the baseline point.
+func (f *Font) print(shader *Shader, point Point, scale float64, text string) {
+	indices := []rune(text)
 	if len(indices) == 0 {
 		return
 	}
@@ -120,8 +237,9 @@ func (f *Font) Printf(point Point, color Color, scale float64, fs string, argv .
 		runeIndex := rune(indices[i])
 
 		// if rune is not in range
-		if int(runeIndex) > maxchar {
-			fmt.Println("%c %d\n", runeIndex, runeIndex)
+		if int(runeIndex) >= maxchar {
+			log.Printf("Font.Printf: unsupported character %q", runeIndex)
+			continue
 		}
 
 		ch := f.char[runeIndex]
@@ -131,9 +249,7 @@ func (f *Font) Printf(point Point, color Color, scale float64, fs string, argv .
 		h := float64(ch.h) * scale
 
 		rect := Rect{xpos, ypos, xpos + w, ypos + h}
-		tex := Texture{Rectangle(rect), float32(w), float32(h), ch.tex}
-
-		fontShader.setUniformVec("textColor", float32(color.R), float32(color.G), float32(color.B), float32(color.A))
+		tex := Texture{VertexObject: Rectangle(rect), width: float32(w), height: float32(h), tex: ch.tex}
 
 		vertBuffer.loadVertexArray(tex.vertexArray())
 		vertBuffer.loadUVs([]float32{
@@ -142,6 +258,9 @@ func (f *Font) Printf(point Point, color Color, scale float64, fs string, argv .
 			1.0, 0.0,
 			1.0, 1.0,
 		})
-		fontShader.drawTexture(vertBuffer, &tex)
+		shader.drawTexture(vertBuffer, &tex)
+
+		// Advance is in 26.6 fixed point.
+		point.X += float64(ch.adv) / 64 * scale
 	}
 }
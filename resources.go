@@ -0,0 +1,123 @@
+package layergl
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// If set, a GPU object garbage collected without being deleted causes a
+// panic instead of a log message.
+var PanicOnLeak bool
+
+// resource is a GPU object owned by the package. Finalizers only report the
+// leak: GL objects cannot be deleted from the finalizer goroutine.
+type resource struct {
+	id      uint64
+	free    func()
+	deleted bool
+}
+
+type resourceInfo struct {
+	kind, name string
+}
+
+var (
+	resourcesMu   sync.Mutex
+	resourceID    uint64
+	liveResources = make(map[uint64]resourceInfo)
+)
+
+// Registers the object, free is called once on release.
+func trackResource(kind, name string, free func()) *resource {
+	resourcesMu.Lock()
+	resourceID++
+	r := &resource{id: resourceID, free: free}
+	liveResources[r.id] = resourceInfo{kind, name}
+	resourcesMu.Unlock()
+
+	runtime.SetFinalizer(r, finalizeResource)
+	return r
+}
+
+func finalizeResource(r *resource) {
+	if r.deleted {
+		return
+	}
+
+	resourcesMu.Lock()
+	info := liveResources[r.id]
+	resourcesMu.Unlock()
+
+	msg := fmt.Sprintf("layergl: %v garbage collected without Delete", info)
+	if PanicOnLeak {
+		panic(msg)
+	}
+	log.Println(msg)
+}
+
+// Deletes the GL object. Safe to call more than once and on nil.
+func (r *resource) release() {
+	if r == nil || r.deleted {
+		return
+	}
+
+	r.deleted = true
+	r.free()
+
+	resourcesMu.Lock()
+	delete(liveResources, r.id)
+	resourcesMu.Unlock()
+}
+
+func (i resourceInfo) String() string {
+	if i.name == "" {
+		return i.kind
+	}
+
+	return fmt.Sprintf("%s %q", i.kind, i.name)
+}
+
+// Returns descriptions of the GPU objects created by the package that have
+// not been deleted yet.
+func LiveResources() []string {
+	resourcesMu.Lock()
+	ids := make([]uint64, 0, len(liveResources))
+	for id := range liveResources {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	list := make([]string, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, liveResources[id].String())
+	}
+	resourcesMu.Unlock()
+
+	return list
+}
+
+// Deletes the objects created by Init and logs every object still alive,
+// returning their number. Call before destroying the GL context.
+func Terminate() int {
+	for _, b := range builtinShaders() {
+		if *b.shader != nil {
+			(*b.shader).Delete()
+		}
+	}
+	if vertBuffer != nil {
+		vertBuffer.delete()
+	}
+	if instanceBuffer != nil {
+		instanceBuffer.delete()
+	}
+
+	leaks := LiveResources()
+	for _, leak := range leaks {
+		log.Println("layergl: leaked", leak)
+	}
+
+	return len(leaks)
+}
@@ -0,0 +1,273 @@
+package layergl
+
+import (
+	"sort"
+)
+
+// Drawable is the content of a scene node. Draw is called with the world
+// transform of the node pushed and the inherited opacity of the node.
+type Drawable interface {
+	Draw(opacity float64)
+}
+
+// Shape draws VertexObject filled with the color.
+type Shape struct {
+	Geometry *VertexObject
+	Color    Color
+}
+
+func (s *Shape) Draw(opacity float64) {
+	color := s.Color
+	color.A *= opacity
+	DrawVertexObject(s.Geometry, color)
+}
+
+// Sprite draws the texture, which may be an AnimatedSprite's Texture.
+type Sprite struct {
+	Texture *Texture
+}
+
+func (s *Sprite) Draw(opacity float64) {
+	textureShader.SetFloat("transparency", float32(1-opacity))
+	DrawTexture(s.Texture)
+	textureShader.SetFloat("transparency", 0)
+}
+
+// Text prints the string with its baseline starting at the node origin.
+type Text struct {
+	Font  *Font
+	Text  string
+	Color Color
+	Scale float64
+}
+
+func (t *Text) Draw(opacity float64) {
+	color := t.Color
+	color.A *= opacity
+	t.Font.Printf(Point{}, color, t.Scale, "%s", t.Text)
+}
+
+// Node is an element of the scene graph. Its transform, visibility and
+// opacity are relative to the parent. A node without content groups its
+// children.
+type Node struct {
+	Name    string
+	Content Drawable
+
+	parent   *Node
+	children []*Node
+
+	transform Transform
+	hidden    bool
+	opacity   float64
+
+	// World transform, valid unless dirty.
+	world Transform
+	dirty bool
+}
+
+// Creates visible, opaque Node with identity transform.
+func NewNode(name string, content Drawable) *Node {
+	return &Node{Name: name, Content: content, transform: Identity(), opacity: 1, dirty: true}
+}
+
+// Adds the child to the end of the children, drawn after the others. The
+// child is removed from its previous parent.
+func (n *Node) Add(child *Node) {
+	if child.parent != nil {
+		child.parent.Remove(child)
+	}
+
+	child.parent = n
+	child.markDirty()
+	n.children = append(n.children, child)
+}
+
+// Removes the child, if it is a child of n.
+func (n *Node) Remove(child *Node) {
+	for i, c := range n.children {
+		if c == child {
+			n.children = append(n.children[:i], n.children[i+1:]...)
+			child.parent = nil
+			child.markDirty()
+			return
+		}
+	}
+}
+
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// Returns the first descendant with the name, searched depth first.
+func (n *Node) Find(name string) *Node {
+	for _, c := range n.children {
+		if c.Name == name {
+			return c
+		}
+		if found := c.Find(name); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// Returns the transform relative to the parent.
+func (n *Node) Transform() Transform {
+	return n.transform
+}
+
+func (n *Node) SetTransform(t Transform) {
+	n.transform = t
+	n.markDirty()
+}
+
+// Returns the transform relative to the root of the graph.
+func (n *Node) WorldTransform() Transform {
+	if n.dirty {
+		if n.parent != nil {
+			n.world = n.parent.WorldTransform().Mul(n.transform)
+		} else {
+			n.world = n.transform
+		}
+		n.dirty = false
+	}
+
+	return n.world
+}
+
+// Invalidates world transforms of the node and its descendants. Descendants
+// of a dirty node are dirty already.
+func (n *Node) markDirty() {
+	if n.dirty {
+		return
+	}
+
+	n.dirty = true
+	for _, c := range n.children {
+		c.markDirty()
+	}
+}
+
+// Returns whether the node itself is visible, regardless of its ancestors.
+func (n *Node) Visible() bool {
+	return !n.hidden
+}
+
+// Hides or shows the node and its descendants.
+func (n *Node) SetVisible(visible bool) {
+	n.hidden = !visible
+}
+
+// Returns the opacity relative to the parent.
+func (n *Node) Opacity() float64 {
+	return n.opacity
+}
+
+// Sets the opacity, from 0 to 1, multiplied with the opacity of the ancestors.
+func (n *Node) SetOpacity(opacity float64) {
+	n.opacity = opacity
+}
+
+func (n *Node) draw(opacity float64) {
+	if n.hidden {
+		return
+	}
+
+	opacity *= n.opacity
+	if opacity <= 0 {
+		return
+	}
+
+	if n.Content != nil {
+		PushTransform(n.WorldTransform())
+		n.Content.Draw(opacity)
+		PopTransform()
+	}
+
+	for _, c := range n.children {
+		c.draw(opacity)
+	}
+}
+
+// Layer is a named scene graph drawn as a whole.
+type Layer struct {
+	Name string
+
+	// Layers are drawn in increasing Z, layers with equal Z in the order they
+	// were added.
+	Z int
+
+	// Multiplier of the camera offset applied to the layer: 1 moves the layer
+	// with the camera, 0 keeps it fixed on screen, values in between give
+	// depth to backgrounds.
+	Parallax Point
+
+	Root *Node
+
+	Hidden bool
+}
+
+// Scene is a set of layers composited by DrawScene.
+type Scene struct {
+	// Position of the camera, the scene is drawn shifted by its negation.
+	Camera Point
+
+	layers []*Layer
+}
+
+func NewScene() *Scene {
+	return new(Scene)
+}
+
+// Adds new empty layer moving with the camera.
+func (s *Scene) AddLayer(name string, z int) *Layer {
+	layer := &Layer{Name: name, Z: z, Parallax: Point{1, 1}, Root: NewNode(name, nil)}
+	s.layers = append(s.layers, layer)
+	return layer
+}
+
+// Returns the layer with the name, or nil.
+func (s *Scene) Layer(name string) *Layer {
+	for _, l := range s.layers {
+		if l.Name == name {
+			return l
+		}
+	}
+
+	return nil
+}
+
+func (s *Scene) RemoveLayer(name string) {
+	for i, l := range s.layers {
+		if l.Name == name {
+			s.layers = append(s.layers[:i], s.layers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns the layers in drawing order.
+func (s *Scene) Layers() []*Layer {
+	layers := append([]*Layer(nil), s.layers...)
+	sort.SliceStable(layers, func(i, j int) bool { return layers[i].Z < layers[j].Z })
+	return layers
+}
+
+// Draws visible layers of the scene on top of the current transform.
+func DrawScene(s *Scene) {
+	for _, l := range s.Layers() {
+		if l.Hidden || l.Root == nil {
+			continue
+		}
+
+		PushTransform(Translation(-s.Camera.X*l.Parallax.X, -s.Camera.Y*l.Parallax.Y))
+		l.Root.draw(1)
+		PopTransform()
+	}
+}
@@ -0,0 +1,375 @@
+package layergl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Version of the scene file format written by SaveScene.
+const SceneVersion = 1
+
+// Scene file layout. Points are [x, y], colors [r, g, b, a], texture regions
+// [x, y, width, height] and transforms [a, b, c, d, e, f], see Transform.
+// Nodes without a transform have the identity transformation, layers without
+// parallax move with the camera, sprites without size have the size of the
+// region in pixels and texts without scale are not scaled.
+type sceneFile struct {
+	Version int         `json:"version"`
+	Camera  [2]float64  `json:"camera"`
+	Layers  []layerFile `json:"layers"`
+}
+
+type layerFile struct {
+	Name     string      `json:"name"`
+	Z        int         `json:"z"`
+	Parallax *[2]float64 `json:"parallax,omitempty"`
+	Hidden   bool        `json:"hidden,omitempty"`
+	Root     nodeFile    `json:"root"`
+}
+
+type nodeFile struct {
+	Name      string          `json:"name,omitempty"`
+	Transform *[6]float64     `json:"transform,omitempty"`
+	Hidden    bool            `json:"hidden,omitempty"`
+	Opacity   *float64        `json:"opacity,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+	Children  []nodeFile      `json:"children,omitempty"`
+}
+
+type contentType struct {
+	Type string `json:"type"`
+}
+
+type shapeFile struct {
+	Type     string       `json:"type"`
+	Color    [4]float64   `json:"color"`
+	Vertices [][2]float64 `json:"vertices"`
+	Indices  []int        `json:"indices"`
+	Colors   [][4]float64 `json:"colors,omitempty"`
+	UVs      [][2]float64 `json:"uvs,omitempty"`
+}
+
+type spriteFile struct {
+	Type    string      `json:"type"`
+	Texture string      `json:"texture"`
+	Size    *[2]float64 `json:"size,omitempty"`
+	Region  *[4]int     `json:"region,omitempty"`
+	FlipX   bool        `json:"flipX,omitempty"`
+	FlipY   bool        `json:"flipY,omitempty"`
+	Scroll  *[2]float64 `json:"scroll,omitempty"`
+	Repeat  *[2]float64 `json:"repeat,omitempty"`
+}
+
+type textFile struct {
+	Type     string     `json:"type"`
+	Font     string     `json:"font"`
+	FontSize int32      `json:"fontSize"`
+	Text     string     `json:"text"`
+	Color    [4]float64 `json:"color"`
+	Scale    *float64   `json:"scale,omitempty"`
+}
+
+// Writes the scene as indented JSON. Textures and fonts are referenced by
+// their paths, content other than Shape, Sprite and Text is not supported.
+func SaveScene(w io.Writer, s *Scene) error {
+	file := sceneFile{Version: SceneVersion, Camera: [2]float64{s.Camera.X, s.Camera.Y}}
+	for _, l := range s.layers {
+		lf := layerFile{
+			Name:   l.Name,
+			Z:      l.Z,
+			Hidden: l.Hidden,
+		}
+		if l.Parallax != (Point{1, 1}) {
+			lf.Parallax = &[2]float64{l.Parallax.X, l.Parallax.Y}
+		}
+		if l.Root != nil {
+			root, err := saveNode(l.Root)
+			if err != nil {
+				return fmt.Errorf("SaveScene: layer %q: %v", l.Name, err)
+			}
+			lf.Root = root
+		}
+		file.Layers = append(file.Layers, lf)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(file)
+}
+
+func saveNode(n *Node) (nodeFile, error) {
+	nf := nodeFile{
+		Name:   n.Name,
+		Hidden: n.hidden,
+	}
+	if t := n.transform; t != Identity() {
+		nf.Transform = &[6]float64{t.A, t.B, t.C, t.D, t.E, t.F}
+	}
+	if n.opacity != 1 {
+		opacity := n.opacity
+		nf.Opacity = &opacity
+	}
+
+	if n.Content != nil {
+		content, err := saveContent(n.Content)
+		if err != nil {
+			return nf, fmt.Errorf("node %q: %v", n.Name, err)
+		}
+		if nf.Content, err = json.Marshal(content); err != nil {
+			return nf, fmt.Errorf("node %q: %v", n.Name, err)
+		}
+	}
+
+	for _, c := range n.children {
+		child, err := saveNode(c)
+		if err != nil {
+			return nf, err
+		}
+		nf.Children = append(nf.Children, child)
+	}
+
+	return nf, nil
+}
+
+func saveContent(d Drawable) (interface{}, error) {
+	switch d := d.(type) {
+	case *Shape:
+		sf := shapeFile{Type: "shape", Color: colorArray(d.Color)}
+		if d.Geometry != nil {
+			sf.Vertices = pointArrays(d.Geometry.Vertices)
+			sf.Indices = d.Geometry.Indices
+			sf.UVs = pointArrays(d.Geometry.UVs)
+			for _, c := range d.Geometry.Colors {
+				sf.Colors = append(sf.Colors, colorArray(c))
+			}
+		}
+		return sf, nil
+
+	case *Sprite:
+		t := d.Texture
+		if t == nil || t.Path == "" {
+			return nil, fmt.Errorf("sprite texture has no asset path")
+		}
+		sf := spriteFile{
+			Type:    "sprite",
+			Texture: t.Path,
+			Size:    &[2]float64{float64(t.width), float64(t.height)},
+			FlipX:   t.FlipX,
+			FlipY:   t.FlipY,
+		}
+		if !t.Region.empty() {
+			r := t.Region
+			sf.Region = &[4]int{r.X, r.Y, r.Width, r.Height}
+		}
+		if t.Scroll != (Point{}) {
+			sf.Scroll = &[2]float64{t.Scroll.X, t.Scroll.Y}
+		}
+		if t.Repeat != (Point{}) {
+			sf.Repeat = &[2]float64{t.Repeat.X, t.Repeat.Y}
+		}
+		return sf, nil
+
+	case *Text:
+		if d.Font == nil || d.Font.path == "" {
+			return nil, fmt.Errorf("text font has no asset path")
+		}
+		tf := textFile{
+			Type:     "text",
+			Font:     d.Font.path,
+			FontSize: d.Font.scale,
+			Text:     d.Text,
+			Color:    colorArray(d.Color),
+		}
+		if d.Scale != 1 {
+			scale := d.Scale
+			tf.Scale = &scale
+		}
+		return tf, nil
+	}
+
+	return nil, fmt.Errorf("cannot save content of type %T", d)
+}
+
+// Reads scene written by SaveScene, loading the assets with the loader.
+// Unknown fields and content types are errors.
+func LoadScene(r io.Reader, loader AssetLoader) (*Scene, error) {
+	var file sceneFile
+	if err := decodeStrict(r, &file); err != nil {
+		return nil, fmt.Errorf("LoadScene: %v", err)
+	}
+	if file.Version < 1 || file.Version > SceneVersion {
+		return nil, fmt.Errorf("LoadScene: unsupported version %d", file.Version)
+	}
+
+	s := NewScene()
+	s.Camera = Point{file.Camera[0], file.Camera[1]}
+	for _, lf := range file.Layers {
+		root, err := loadNode(lf.Root, loader)
+		if err != nil {
+			return nil, fmt.Errorf("LoadScene: layer %q: %v", lf.Name, err)
+		}
+
+		l := &Layer{
+			Name:     lf.Name,
+			Z:        lf.Z,
+			Parallax: Point{1, 1},
+			Hidden:   lf.Hidden,
+			Root:     root,
+		}
+		if p := lf.Parallax; p != nil {
+			l.Parallax = Point{p[0], p[1]}
+		}
+		s.layers = append(s.layers, l)
+	}
+
+	return s, nil
+}
+
+func loadNode(nf nodeFile, loader AssetLoader) (*Node, error) {
+	n := NewNode(nf.Name, nil)
+	if t := nf.Transform; t != nil {
+		n.transform = Transform{t[0], t[1], t[2], t[3], t[4], t[5]}
+	}
+	n.hidden = nf.Hidden
+	if nf.Opacity != nil {
+		n.opacity = *nf.Opacity
+	}
+
+	if len(nf.Content) != 0 {
+		content, err := loadContent(nf.Content, loader)
+		if err != nil {
+			return nil, fmt.Errorf("node %q: %v", nf.Name, err)
+		}
+		n.Content = content
+	}
+
+	for _, cf := range nf.Children {
+		child, err := loadNode(cf, loader)
+		if err != nil {
+			return nil, err
+		}
+		n.Add(child)
+	}
+
+	return n, nil
+}
+
+func loadContent(data json.RawMessage, loader AssetLoader) (Drawable, error) {
+	var ct contentType
+	if err := json.Unmarshal(data, &ct); err != nil {
+		return nil, err
+	}
+
+	switch ct.Type {
+	case "shape":
+		var sf shapeFile
+		if err := decodeStrict(bytes.NewReader(data), &sf); err != nil {
+			return nil, err
+		}
+		v := &VertexObject{
+			Vertices: points(sf.Vertices),
+			Indices:  sf.Indices,
+			UVs:      points(sf.UVs),
+		}
+		for _, c := range sf.Colors {
+			v.Colors = append(v.Colors, colorFromArray(c))
+		}
+		return &Shape{Geometry: v, Color: colorFromArray(sf.Color)}, nil
+
+	case "sprite":
+		var sf spriteFile
+		if err := decodeStrict(bytes.NewReader(data), &sf); err != nil {
+			return nil, err
+		}
+		if loader == nil {
+			return nil, fmt.Errorf("missing texture %q: no asset loader", sf.Texture)
+		}
+		tex, err := loader.LoadTexture(sf.Texture)
+		if err != nil {
+			return nil, fmt.Errorf("missing texture %q: %v", sf.Texture, err)
+		}
+		tex.Path = sf.Texture
+
+		var region TextureRegion
+		width, height := tex.Size()
+		if r := sf.Region; r != nil {
+			region = TextureRegion{r[0], r[1], r[2], r[3]}
+			width, height = r[2], r[3]
+		}
+		size := [2]float64{float64(width), float64(height)}
+		if sf.Size != nil {
+			size = *sf.Size
+		}
+		t := tex.SubTexture(region, size[0], size[1])
+		t.FlipX, t.FlipY = sf.FlipX, sf.FlipY
+		if sf.Scroll != nil {
+			t.Scroll = Point{sf.Scroll[0], sf.Scroll[1]}
+		}
+		if sf.Repeat != nil {
+			t.Repeat = Point{sf.Repeat[0], sf.Repeat[1]}
+		}
+		return &Sprite{Texture: t}, nil
+
+	case "text":
+		var tf textFile
+		if err := decodeStrict(bytes.NewReader(data), &tf); err != nil {
+			return nil, err
+		}
+		if loader == nil {
+			return nil, fmt.Errorf("missing font %q: no asset loader", tf.Font)
+		}
+		font, err := loader.LoadFont(tf.Font, tf.FontSize)
+		if err != nil {
+			return nil, fmt.Errorf("missing font %q: %v", tf.Font, err)
+		}
+		font.path, font.scale = tf.Font, tf.FontSize
+		scale := 1.0
+		if tf.Scale != nil {
+			scale = *tf.Scale
+		}
+		return &Text{Font: font, Text: tf.Text, Color: colorFromArray(tf.Color), Scale: scale}, nil
+	}
+
+	return nil, fmt.Errorf("unknown content type %q", ct.Type)
+}
+
+// Decodes single JSON value, rejecting unknown fields.
+func decodeStrict(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func pointArrays(points []Point) [][2]float64 {
+	if points == nil {
+		return nil
+	}
+
+	a := make([][2]float64, len(points))
+	for i, p := range points {
+		a[i] = [2]float64{p.X, p.Y}
+	}
+	return a
+}
+
+func points(a [][2]float64) []Point {
+	if a == nil {
+		return nil
+	}
+
+	points := make([]Point, len(a))
+	for i, p := range a {
+		points[i] = Point{p[0], p[1]}
+	}
+	return points
+}
+
+func colorArray(c Color) [4]float64 {
+	return [4]float64{c.R, c.G, c.B, c.A}
+}
+
+func colorFromArray(a [4]float64) Color {
+	return Color{a[0], a[1], a[2], a[3]}
+}
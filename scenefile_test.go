@@ -0,0 +1,133 @@
+package layergl
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestLoadSceneDefaultTransform(t *testing.T) {
+	const file = `{
+  "version": 1,
+  "camera": [0, 0],
+  "layers": [{
+    "name": "world",
+    "z": 0,
+    "parallax": [1, 1],
+    "root": {
+      "children": [{
+        "name": "moved",
+        "transform": [1, 0, 0, 1, 10, 20],
+        "children": [{"name": "child"}]
+      }]
+    }
+  }]
+}`
+
+	s, err := LoadScene(strings.NewReader(file), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := s.Layer("world").Root
+	if tr := root.Transform(); tr != Identity() {
+		t.Errorf("root transform = %v, want identity", tr)
+	}
+	child := root.Find("child")
+	if child == nil {
+		t.Fatal("child not found")
+	}
+	if tr := child.WorldTransform(); tr != Translation(10, 20) {
+		t.Errorf("child world transform = %v, want translation by 10, 20", tr)
+	}
+
+	var buf bytes.Buffer
+	if err := SaveScene(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), `"transform"`); n != 1 {
+		t.Errorf("saved scene has %d transforms, want only the non-identity one:\n%s", n, buf.String())
+	}
+}
+
+// Loads assets without a GPU: textures are 32x16 pixels.
+type fakeLoader struct{}
+
+func (fakeLoader) LoadTexture(path string) (*Texture, error) {
+	return &Texture{size: image.Point{32, 16}}, nil
+}
+
+func (fakeLoader) LoadFont(path string, scale int32) (*Font, error) {
+	return &Font{}, nil
+}
+
+func TestLoadSceneDefaults(t *testing.T) {
+	const file = `{
+  "version": 1,
+  "camera": [0, 0],
+  "layers": [{
+    "name": "default",
+    "z": 0,
+    "root": {
+      "children": [
+        {"name": "sprite", "content": {"type": "sprite", "texture": "a.png"}},
+        {"name": "region", "content": {"type": "sprite", "texture": "a.png", "region": [8, 0, 8, 4]}},
+        {"name": "sized", "content": {"type": "sprite", "texture": "a.png", "size": [5, 6]}},
+        {"name": "text", "content": {"type": "text", "font": "a.ttf", "fontSize": 12, "text": "hi", "color": [1, 1, 1, 1]}},
+        {"name": "scaled", "content": {"type": "text", "font": "a.ttf", "fontSize": 12, "text": "hi", "color": [1, 1, 1, 1], "scale": 2}}
+      ]
+    }
+  }, {
+    "name": "background",
+    "z": -1,
+    "parallax": [0.5, 0],
+    "root": {}
+  }]
+}`
+
+	s, err := LoadScene(strings.NewReader(file), fakeLoader{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p := s.Layer("default").Parallax; p != (Point{1, 1}) {
+		t.Errorf("default parallax = %v, want 1, 1", p)
+	}
+	if p := s.Layer("background").Parallax; p != (Point{0.5, 0}) {
+		t.Errorf("parallax = %v, want 0.5, 0", p)
+	}
+
+	root := s.Layer("default").Root
+	sprites := []struct {
+		name          string
+		width, height float32
+	}{
+		{"sprite", 32, 16},
+		{"region", 8, 4},
+		{"sized", 5, 6},
+	}
+	for _, sp := range sprites {
+		tex := root.Find(sp.name).Content.(*Sprite).Texture
+		if tex.width != sp.width || tex.height != sp.height {
+			t.Errorf("%s: size %vx%v, want %vx%v", sp.name, tex.width, tex.height, sp.width, sp.height)
+		}
+	}
+	if scale := root.Find("text").Content.(*Text).Scale; scale != 1 {
+		t.Errorf("default text scale = %v, want 1", scale)
+	}
+	if scale := root.Find("scaled").Content.(*Text).Scale; scale != 2 {
+		t.Errorf("text scale = %v, want 2", scale)
+	}
+
+	// Defaults are left out when saving.
+	var buf bytes.Buffer
+	if err := SaveScene(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]int{`"parallax"`: 1, `"scale"`: 1} {
+		if n := strings.Count(buf.String(), key); n != want {
+			t.Errorf("saved scene has %d %s keys, want %d:\n%s", n, key, want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package layergl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type ShaderStage int
+
+const (
+	StageVertex ShaderStage = iota
+	StageFragment
+	StageLink
+)
+
+func (s ShaderStage) String() string {
+	switch s {
+	case StageVertex:
+		return "vertex shader"
+	case StageFragment:
+		return "fragment shader"
+	case StageLink:
+		return "program link"
+	}
+
+	return "unknown stage"
+}
+
+// ShaderError is returned when a shader fails to compile or a program fails
+// to link. Log holds the raw driver output, Messages the lines of it that
+// could be attributed to a position in the source.
+type ShaderError struct {
+	Stage    ShaderStage
+	Log      string
+	Messages []ShaderMessage
+}
+
+// Single diagnostic from the driver info log. Line and Column are 1-based,
+// zero if the driver did not report them. Context holds the offending source
+// line together with the lines around it.
+type ShaderMessage struct {
+	Line, Column int
+	Text         string
+	Context      string
+}
+
+// Lines of source shown before and after the offending line.
+const shaderErrorContext = 2
+
+// Info log formats used by the common drivers:
+//
+//	0:12(5): error: ...          Mesa
+//	0(12) : error C0000: ...     NVIDIA
+//	ERROR: 0:12: ...             AMD, Intel, Apple
+var shaderLogPatterns = []struct {
+	re           *regexp.Regexp
+	line, column int
+}{
+	{regexp.MustCompile(`^\d+:(\d+)\((\d+)\): (.*)$`), 1, 2},
+	{regexp.MustCompile(`^\d+\((\d+)\) ?: (.*)$`), 1, 0},
+	{regexp.MustCompile(`^(?:ERROR|WARNING): \d+:(\d+): (.*)$`), 1, 0},
+}
+
+func newShaderError(stage ShaderStage, log, source string) *ShaderError {
+	err := &ShaderError{Stage: stage, Log: strings.TrimSpace(log)}
+
+	sourceLines := strings.Split(source, "\n")
+	for _, line := range strings.Split(err.Log, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		err.Messages = append(err.Messages, parseShaderMessage(line, sourceLines))
+	}
+
+	return err
+}
+
+func parseShaderMessage(line string, source []string) (msg ShaderMessage) {
+	msg.Text = line
+
+	for _, p := range shaderLogPatterns {
+		m := p.re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		msg.Line, _ = strconv.Atoi(m[p.line])
+		if p.column != 0 {
+			msg.Column, _ = strconv.Atoi(m[p.column])
+		}
+		msg.Text = m[len(m)-1]
+		msg.Context = sourceContext(source, msg.Line)
+		break
+	}
+
+	return msg
+}
+
+// Returns numbered source lines around the line n, marking the line itself.
+func sourceContext(source []string, n int) string {
+	if n < 1 || n > len(source) {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := n - shaderErrorContext; i <= n+shaderErrorContext; i++ {
+		if i < 1 || i > len(source) {
+			continue
+		}
+
+		marker := " "
+		if i == n {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %4d | %s\n", marker, i, source[i-1])
+	}
+
+	return b.String()
+}
+
+func (e *ShaderError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("%v failed", e.Stage)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v failed:", e.Stage)
+	for _, msg := range e.Messages {
+		b.WriteString("\n")
+		if msg.Line != 0 {
+			fmt.Fprintf(&b, "%d:", msg.Line)
+			if msg.Column != 0 {
+				fmt.Fprintf(&b, "%d:", msg.Column)
+			}
+			b.WriteString(" ")
+		}
+		b.WriteString(msg.Text)
+		if msg.Context != "" {
+			b.WriteString("\n")
+			b.WriteString(strings.TrimRight(msg.Context, "\n"))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package layergl
+
+import (
+	"strings"
+	"testing"
+)
+
+const testShaderSource = `#version 330
+out vec4 frag_color;
+uniform vec4 color;
+void main() {
+    frag_color = colr;
+}`
+
+func TestShaderErrorMessages(t *testing.T) {
+	context := "     3 | uniform vec4 color;\n" +
+		"     4 | void main() {\n" +
+		">    5 |     frag_color = colr;\n" +
+		"     6 | }\n"
+
+	tests := []struct {
+		driver       string
+		log          string
+		line, column int
+		text         string
+		context      string
+	}{
+		{
+			"Mesa",
+			`0:5(18): error: ` + "`colr'" + ` undeclared`,
+			5, 18, "error: `colr' undeclared", context,
+		},
+		{
+			"NVIDIA",
+			`0(5) : error C1008: undefined variable "colr"`,
+			5, 0, `error C1008: undefined variable "colr"`, context,
+		},
+		{
+			"AMD",
+			`ERROR: 0:5: 'colr' : undeclared identifier`,
+			5, 0, `'colr' : undeclared identifier`, context,
+		},
+		{
+			"Apple",
+			`WARNING: 0:1: extension 'GL_ARB_gpu_shader5' is not supported`,
+			1, 0, `extension 'GL_ARB_gpu_shader5' is not supported`,
+			">    1 | #version 330\n     2 | out vec4 frag_color;\n     3 | uniform vec4 color;\n",
+		},
+		{
+			"line out of source",
+			`0:40(1): error: unexpected end of file`,
+			40, 1, "error: unexpected end of file", "",
+		},
+		{
+			"link",
+			`error: fragment shader output "frag_color" is not written`,
+			0, 0, `error: fragment shader output "frag_color" is not written`, "",
+		},
+	}
+
+	for _, test := range tests {
+		err := newShaderError(StageFragment, "\n"+test.log+"\n\n", testShaderSource)
+		if err.Log != test.log || len(err.Messages) != 1 {
+			t.Errorf("%s: log %q with %d messages", test.driver, err.Log, len(err.Messages))
+			continue
+		}
+
+		msg := err.Messages[0]
+		if msg.Line != test.line || msg.Column != test.column || msg.Text != test.text {
+			t.Errorf("%s: parsed %d:%d %q, want %d:%d %q", test.driver, msg.Line, msg.Column, msg.Text, test.line, test.column, test.text)
+		}
+		if msg.Context != test.context {
+			t.Errorf("%s: context\n%s\nwant\n%s", test.driver, msg.Context, test.context)
+		}
+	}
+}
+
+func TestShaderErrorString(t *testing.T) {
+	log := "0:5(18): error: `colr' undeclared\n0:5(5): error: type mismatch"
+	err := newShaderError(StageFragment, log, testShaderSource)
+
+	s := err.Error()
+	if !strings.HasPrefix(s, "fragment shader failed:\n5:18: error: `colr' undeclared\n") {
+		t.Errorf("unexpected error:\n%s", s)
+	}
+	if n := strings.Count(s, ">    5 |"); n != 2 {
+		t.Errorf("offending line marked %d times:\n%s", n, s)
+	}
+
+	if s := newShaderError(StageLink, "", "").Error(); s != "program link failed" {
+		t.Errorf("empty log: %q", s)
+	}
+}
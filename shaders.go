@@ -3,118 +3,404 @@ package layergl
 import (
 	"fmt"
 	"github.com/go-gl/gl/v3.3-core/gl"
+	"log"
 	"strings"
 )
 
-type shader uint32
+// Shader is a linked GLSL program. Vertex shaders receive the position as
+// attribute 0 (vec2) and texture coordinates as attribute 1 (vec2); a
+// "projection" uniform of type mat4, if declared, is set by the renderer.
+type Shader struct {
+	program  uint32
+	res      *resource
+	uniforms map[string]uniform
+	missing  map[string]bool
+
+	// Last values uploaded to each uniform location.
+	floats map[int32][]float32
+	ints   map[int32][]int32
+}
+
+// Active uniform as reported by glGetActiveUniform.
+type uniform struct {
+	location int32
+	xtype    uint32
+	size     int32
+}
+
+// Compiles and links a new Shader from vertex and fragment GLSL sources.
+func NewShader(vertexSource, fragmentSource string) (*Shader, error) {
+	vertexShader, err := compileShader(vertexSource, gl.VERTEX_SHADER)
+	if err != nil {
+		return nil, err
+	}
+	defer gl.DeleteShader(vertexShader)
+
+	fragmentShader, err := compileShader(fragmentSource, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return nil, err
+	}
+	defer gl.DeleteShader(fragmentShader)
+
+	program, err := linkProgram(vertexShader, fragmentShader)
+	if err != nil {
+		return nil, err
+	}
 
-func (v shader) setUniformMat(name string, val []float32) error {
-	v.bind()
+	s := &Shader{program: program}
+	s.res = trackResource("shader", "", func() { state.deleteProgram(program) })
+	s.reflect()
+
+	return s, nil
+}
+
+// Deletes the program. The shader must not be used afterwards.
+func (s *Shader) Delete() {
+	s.res.release()
+}
+
+// Queries the list of active uniforms of the program.
+func (s *Shader) reflect() {
+	s.uniforms = make(map[string]uniform)
+	s.missing = make(map[string]bool)
+	s.floats = make(map[int32][]float32)
+	s.ints = make(map[int32][]int32)
+
+	var count, maxLength int32
+	gl.GetProgramiv(s.program, gl.ACTIVE_UNIFORMS, &count)
+	gl.GetProgramiv(s.program, gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+
+	buf := make([]uint8, maxLength+1)
+	for i := int32(0); i < count; i++ {
+		var length, size int32
+		var xtype uint32
+		gl.GetActiveUniform(s.program, uint32(i), maxLength+1, &length, &size, &xtype, &buf[0])
+
+		name := string(buf[:length])
+		location := gl.GetUniformLocation(s.program, gl.Str(name+"\x00"))
+
+		// Arrays are reported as "name[0]".
+		name = strings.TrimSuffix(name, "[0]")
+		s.uniforms[name] = uniform{location, xtype, size}
+	}
+}
+
+// Returns true if the program has an active uniform with the given name.
+func (s *Shader) HasUniform(name string) bool {
+	_, ok := s.uniforms[name]
+	return ok
+}
+
+// Looks up uniform and checks its type. Missing uniforms are logged only once
+// per shader so that setting them every frame does not flood the output.
+func (s *Shader) lookup(name string, kinds ...uint32) (uniform, error) {
+	u, ok := s.uniforms[name]
+	if !ok {
+		err := fmt.Errorf("uniform %q: not found in shader", name)
+		if !s.missing[name] {
+			s.missing[name] = true
+			log.Println(err)
+		}
+		return u, err
+	}
 
-	location := gl.GetUniformLocation(uint32(v), gl.Str(name+"\x00"))
-	if location == -1 {
-		err := fmt.Errorf("setUniformMat(\"%s\", %v): unable to find uniform location", name, val)
-		fmt.Println(err)
+	for _, kind := range kinds {
+		if u.xtype == kind {
+			return u, nil
+		}
+	}
+
+	return u, fmt.Errorf("uniform %q: cannot assign to %s", name, uniformTypeName(u.xtype))
+}
+
+// Checks the number of values against the size of the uniform and returns
+// the number of elements to upload.
+func (u uniform) count(name string, n, components int) (int32, error) {
+	if n == 0 || n%components != 0 || n/components > int(u.size) {
+		return 0, fmt.Errorf("uniform %q: wrong number of values (%d) for %s", name, n, uniformTypeName(u.xtype))
+	}
+
+	return int32(n / components), nil
+}
+
+// Sets float, vector or float array uniform.
+func (s *Shader) SetFloat(name string, val ...float32) error {
+	u, err := s.lookup(name, gl.FLOAT, gl.FLOAT_VEC2, gl.FLOAT_VEC3, gl.FLOAT_VEC4)
+	if err != nil {
 		return err
 	}
 
-	switch len(val) {
-	case 2 * 2:
-		gl.UniformMatrix2fv(location, 1, false, &val[0])
-	case 3 * 3:
-		gl.UniformMatrix3fv(location, 1, false, &val[0])
-	case 4 * 4:
-		gl.UniformMatrix4fv(location, 1, false, &val[0])
-	default:
-		err := fmt.Errorf("setUniformMat(\"%s\", %v): wrong number of elements in matrix", name, val)
-		fmt.Println(err)
+	components := uniformComponents(u.xtype)
+	count, err := u.count(name, len(val), components)
+	if err != nil {
 		return err
 	}
 
+	if !s.updateFloats(u.location, val) {
+		return nil
+	}
+
+	s.bind()
+
+	switch components {
+	case 1:
+		gl.Uniform1fv(u.location, count, &val[0])
+	case 2:
+		gl.Uniform2fv(u.location, count, &val[0])
+	case 3:
+		gl.Uniform3fv(u.location, count, &val[0])
+	case 4:
+		gl.Uniform4fv(u.location, count, &val[0])
+	}
+
 	return nil
 }
 
-func (v shader) setUniformVec(name string, val ...float32) error {
-	v.bind()
+// Sets integer, integer vector or sampler uniform.
+func (s *Shader) SetInt(name string, val ...int32) error {
+	u, err := s.lookup(name, gl.INT, gl.INT_VEC2, gl.INT_VEC3, gl.INT_VEC4,
+		gl.SAMPLER_2D, gl.SAMPLER_2D_ARRAY, gl.SAMPLER_CUBE, gl.SAMPLER_3D)
+	if err != nil {
+		return err
+	}
 
-	location := gl.GetUniformLocation(uint32(v), gl.Str(name+"\x00"))
-	if location == -1 {
-		err := fmt.Errorf("setUniformVec(\"%s\", %v): unable to find uniform location", name, val)
-		fmt.Println(err)
+	return s.setInts(u, name, val)
+}
+
+// Sets boolean or boolean vector uniform.
+func (s *Shader) SetBool(name string, val ...bool) error {
+	u, err := s.lookup(name, gl.BOOL, gl.BOOL_VEC2, gl.BOOL_VEC3, gl.BOOL_VEC4)
+	if err != nil {
 		return err
 	}
 
-	switch len(val) {
+	ints := make([]int32, len(val))
+	for i, b := range val {
+		if b {
+			ints[i] = 1
+		}
+	}
+
+	return s.setInts(u, name, ints)
+}
+
+func (s *Shader) setInts(u uniform, name string, val []int32) error {
+	components := uniformComponents(u.xtype)
+	count, err := u.count(name, len(val), components)
+	if err != nil {
+		return err
+	}
+
+	if !s.updateInts(u.location, val) {
+		return nil
+	}
+
+	s.bind()
+
+	switch components {
 	case 1:
-		gl.Uniform1f(location, val[0])
+		gl.Uniform1iv(u.location, count, &val[0])
 	case 2:
-		gl.Uniform2f(location, val[0], val[1])
+		gl.Uniform2iv(u.location, count, &val[0])
 	case 3:
-		gl.Uniform3f(location, val[0], val[1], val[2])
+		gl.Uniform3iv(u.location, count, &val[0])
 	case 4:
-		gl.Uniform4f(location, val[0], val[1], val[2], val[3])
-	default:
-		err := fmt.Errorf("setUniformVec(\"%s\", %v): wrong number of arguments", name, val)
-		fmt.Println(err)
+		gl.Uniform4iv(u.location, count, &val[0])
+	}
+
+	return nil
+}
+
+// Sets matrix uniform. Values are in column-major order.
+func (s *Shader) SetMatrix(name string, val []float32) error {
+	u, err := s.lookup(name, gl.FLOAT_MAT2, gl.FLOAT_MAT3, gl.FLOAT_MAT4)
+	if err != nil {
 		return err
 	}
 
+	components := uniformComponents(u.xtype)
+	count, err := u.count(name, len(val), components)
+	if err != nil {
+		return err
+	}
+
+	if !s.updateFloats(u.location, val) {
+		return nil
+	}
+
+	s.bind()
+
+	switch components {
+	case 2 * 2:
+		gl.UniformMatrix2fv(u.location, count, false, &val[0])
+	case 3 * 3:
+		gl.UniformMatrix3fv(u.location, count, false, &val[0])
+	case 4 * 4:
+		gl.UniformMatrix4fv(u.location, count, false, &val[0])
+	}
+
 	return nil
 }
 
-func (v shader) bind() {
-	gl.UseProgram(uint32(v))
+// Sets vec4 uniform to the color.
+func (s *Shader) SetColor(name string, color Color) error {
+	return s.SetFloat(name, float32(color.R), float32(color.G), float32(color.B), float32(color.A))
+}
+
+// Records value of the uniform, returns false if it is already set.
+func (s *Shader) updateFloats(location int32, val []float32) bool {
+	last, ok := s.floats[location]
+	if ok && len(last) == len(val) {
+		equal := true
+		for i := range val {
+			if last[i] != val[i] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return false
+		}
+	}
+
+	s.floats[location] = append(last[:0], val...)
+	return true
+}
+
+func (s *Shader) updateInts(location int32, val []int32) bool {
+	last, ok := s.ints[location]
+	if ok && len(last) == len(val) {
+		equal := true
+		for i := range val {
+			if last[i] != val[i] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return false
+		}
+	}
+
+	s.ints[location] = append(last[:0], val...)
+	return true
+}
+
+func (s *Shader) bind() {
+	state.useProgram(s.program)
 }
 
-func (shader shader) drawTexture(vao *vertexBuffer, texture *Texture) {
+// Binds the shader and sets the projection matrix if the shader uses one.
+func (s *Shader) use() {
+	s.bind()
+	if s.HasUniform("projection") {
+		s.SetMatrix("projection", projectionMatrix)
+	}
+}
+
+func (s *Shader) drawTexture(vao *vertexBuffer, texture *Texture) {
 	texture.bind()
-	shader.bind()
+	s.use()
 	vao.bind()
 
 	gl.DrawElements(gl.TRIANGLES, int32(vao.count), gl.UNSIGNED_INT, nil)
+	countDraw(gl.TRIANGLES, vao.count, 1)
 }
 
-func (shader shader) drawColor(vao *vertexBuffer, color Color) {
-	shader.setUniformVec("color", float32(color.R), float32(color.G), float32(color.B), float32(color.A))
-	vao.bind()
+func (s *Shader) drawColor(vao *vertexBuffer, color Color) {
+	s.SetColor("color", color)
+	s.draw(vao, gl.TRIANGLES)
+}
 
-	gl.DrawElements(gl.TRIANGLES, int32(vao.count), gl.UNSIGNED_INT, nil)
+func (s *Shader) drawLines(vao *vertexBuffer, color Color) {
+	s.SetColor("color", color)
+	s.draw(vao, gl.LINE_STRIP)
 }
 
-func (shader shader) drawLines(vao *vertexBuffer, color Color) {
-	shader.setUniformVec("color", float32(color.R), float32(color.G), float32(color.B), float32(color.A))
+func (s *Shader) drawInstanced(vao *vertexBuffer, count int) {
+	s.use()
 	vao.bind()
 
-	gl.DrawElements(gl.LINE_STRIP, int32(vao.count), gl.UNSIGNED_INT, nil)
+	gl.DrawElementsInstanced(gl.TRIANGLES, int32(vao.count), gl.UNSIGNED_INT, nil, int32(count))
+	countDraw(gl.TRIANGLES, vao.count, count)
 }
 
-// Links vertex and fragment shaders.
-func newShaderProgram(vs, fs string) shader {
-	vertexShader, err := compileShader(vs, gl.VERTEX_SHADER)
-	if err != nil {
-		panic(err)
-	}
+func (s *Shader) draw(vao *vertexBuffer, mode uint32) {
+	s.use()
+	vao.bind()
 
-	fragmentShader, err := compileShader(fs, gl.FRAGMENT_SHADER)
-	if err != nil {
-		panic(err)
+	gl.DrawElements(mode, int32(vao.count), gl.UNSIGNED_INT, nil)
+	countDraw(mode, vao.count, 1)
+}
+
+// Number of scalar components of a uniform type.
+func uniformComponents(xtype uint32) int {
+	switch xtype {
+	case gl.FLOAT_VEC2, gl.INT_VEC2, gl.BOOL_VEC2:
+		return 2
+	case gl.FLOAT_VEC3, gl.INT_VEC3, gl.BOOL_VEC3:
+		return 3
+	case gl.FLOAT_VEC4, gl.INT_VEC4, gl.BOOL_VEC4, gl.FLOAT_MAT2:
+		return 4
+	case gl.FLOAT_MAT3:
+		return 3 * 3
+	case gl.FLOAT_MAT4:
+		return 4 * 4
 	}
 
-	program := gl.CreateProgram()
-	gl.AttachShader(program, vertexShader)
-	gl.AttachShader(program, fragmentShader)
-	gl.LinkProgram(program)
+	return 1
+}
 
-	shader := shader(program)
+// GLSL name of a uniform type, used in error messages.
+func uniformTypeName(xtype uint32) string {
+	switch xtype {
+	case gl.FLOAT:
+		return "float"
+	case gl.FLOAT_VEC2:
+		return "vec2"
+	case gl.FLOAT_VEC3:
+		return "vec3"
+	case gl.FLOAT_VEC4:
+		return "vec4"
+	case gl.INT:
+		return "int"
+	case gl.INT_VEC2:
+		return "ivec2"
+	case gl.INT_VEC3:
+		return "ivec3"
+	case gl.INT_VEC4:
+		return "ivec4"
+	case gl.BOOL:
+		return "bool"
+	case gl.BOOL_VEC2:
+		return "bvec2"
+	case gl.BOOL_VEC3:
+		return "bvec3"
+	case gl.BOOL_VEC4:
+		return "bvec4"
+	case gl.FLOAT_MAT2:
+		return "mat2"
+	case gl.FLOAT_MAT3:
+		return "mat3"
+	case gl.FLOAT_MAT4:
+		return "mat4"
+	case gl.SAMPLER_2D:
+		return "sampler2D"
+	case gl.SAMPLER_2D_ARRAY:
+		return "sampler2DArray"
+	case gl.SAMPLER_3D:
+		return "sampler3D"
+	case gl.SAMPLER_CUBE:
+		return "samplerCube"
+	}
 
-	return shader
+	return fmt.Sprintf("type 0x%X", xtype)
 }
 
 // Compiles a shader.
-func compileShader(data string, shaderType uint32) (uint32, error) {
+func compileShader(source string, shaderType uint32) (uint32, error) {
 	shader := gl.CreateShader(shaderType)
 
-	source := string(data)
 	csource, free := gl.Strs(source + "\x00")
 	gl.ShaderSource(shader, 1, csource, nil)
 	free()
@@ -129,13 +415,52 @@ func compileShader(data string, shaderType uint32) (uint32, error) {
 
 		log := strings.Repeat("\x00", int(logLength)+1)
 		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+		gl.DeleteShader(shader)
 
-		return 0, fmt.Errorf("failed to compile %v: %v", source, log)
+		return 0, newShaderError(shaderStage(shaderType), strings.TrimRight(log, "\x00"), source)
 	}
 
 	return shader, nil
 }
 
+// Links compiled shaders into a program. The shaders are detached afterwards,
+// so they can be deleted by the caller.
+func linkProgram(shaders ...uint32) (uint32, error) {
+	program := gl.CreateProgram()
+	for _, shader := range shaders {
+		gl.AttachShader(program, shader)
+	}
+
+	gl.LinkProgram(program)
+
+	for _, shader := range shaders {
+		gl.DetachShader(program, shader)
+	}
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength)+1)
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+		gl.DeleteProgram(program)
+
+		return 0, newShaderError(StageLink, strings.TrimRight(log, "\x00"), "")
+	}
+
+	return program, nil
+}
+
+func shaderStage(shaderType uint32) ShaderStage {
+	if shaderType == gl.VERTEX_SHADER {
+		return StageVertex
+	}
+
+	return StageFragment
+}
+
 // Shader sources:
 
 const circleFrag = `
@@ -157,12 +482,13 @@ void main() {
 
 const polygonFrag = `
 #version 330
+in vec4 fragColor;
 out vec4 frag_color;
 
 uniform vec4 color;
 
 void main() {
-    frag_color = color;
+    frag_color = color * fragColor;
 }
 `
 
@@ -174,8 +500,25 @@ in vec2 fragTexCoord;
 
 uniform sampler2D tex;
 
+// Drawn part of the texture: left, top, right, bottom.
+uniform vec4 region;
+// Repeat the region for coordinates outside of 0 to 1. Used for atlas
+// regions, whole textures are wrapped by the sampler.
+uniform bool wrap;
+// Fraction of the alpha removed, used for fading.
+uniform float transparency;
+
 void main() {
-    frag_color = texture(tex, vec2(fragTexCoord.x, 1-fragTexCoord.y)); // Flip Y axis
+    // Derivatives of the unwrapped coordinates select the mipmap level, so
+    // that it does not jump at the seams of wrapped regions.
+    vec2 size = region.zw - region.xy;
+    vec2 dx = dFdx(fragTexCoord) * size;
+    vec2 dy = dFdy(fragTexCoord) * size;
+
+    vec2 uv = wrap ? fract(fragTexCoord) : fragTexCoord;
+    uv.y = 1-uv.y; // Flip Y axis
+    frag_color = textureGrad(tex, mix(region.xy, region.zw, uv), dx, dy);
+    frag_color.a *= 1-transparency;
 }
 `
 
@@ -196,35 +539,150 @@ void main() {
 const vertexVert = `
 #version 330
 layout(location = 0) in vec2 vert;
+layout(location = 2) in vec4 vertColor;
+out vec4 fragColor;
 
 uniform mat4 projection;
 
 void main() {
+    fragColor = vertColor;
     gl_Position = projection * vec4(vert, 0.0, 1.0);
 }
 `
 
-const fontVert = `
+// Applies the transform of the instance, given as two rows of the matrix.
+const instanceVert = `
 #version 330
 layout(location = 0) in vec2 vert;
+layout(location = 1) in vec2 vertTexCoord;
+layout(location = 2) in vec4 vertColor;
+layout(location = 3) in vec3 instanceRowX;
+layout(location = 4) in vec3 instanceRowY;
+layout(location = 5) in vec4 instanceColor;
+out vec2 fragTexCoord;
+out vec4 fragColor;
 
-//pass through to fragTexCoord
-in vec2 vertTexCoord;
+uniform mat4 projection;
 
-//window res
-uniform vec2 resolution;
+void main() {
+    vec3 p = vec3(vert, 1.0);
+    fragTexCoord = vertTexCoord;
+    fragColor = vertColor * instanceColor;
+    gl_Position = projection * vec4(dot(instanceRowX, p), dot(instanceRowY, p), 0.0, 1.0);
+}
+`
 
-//pass to frag
+const instanceTextureFrag = `
+#version 330
+in vec2 fragTexCoord;
+in vec4 fragColor;
+out vec4 frag_color;
+
+uniform sampler2D tex;
+uniform vec4 region;
+
+void main() {
+    vec2 uv = vec2(fragTexCoord.x, 1-fragTexCoord.y);
+    frag_color = texture(tex, mix(region.xy, region.zw, uv)) * fragColor;
+}
+`
+
+const fontFrag = `
+#version 330
+out vec4 frag_color;
+
+in vec2 fragTexCoord;
+
+uniform sampler2D tex;
+uniform vec4 textColor;
+
+void main() {
+    // Glyphs are rendered white on black, red channel is the coverage.
+    float a = texture(tex, vec2(fragTexCoord.x, 1-fragTexCoord.y)).r;
+    frag_color = vec4(textColor.rgb, textColor.a * a);
+}
+`
+
+// Passes the untransformed position to the fragment shader for gradients.
+const gradientVert = `
+#version 330
+layout(location = 0) in vec2 vert;
+layout(location = 1) in vec2 vertTexCoord;
+layout(location = 2) in vec4 vertColor;
+out vec2 fragPos;
 out vec2 fragTexCoord;
+out vec4 fragColor;
 
+uniform mat4 projection;
+
+void main() {
+    fragPos = vert;
+    fragTexCoord = vertTexCoord;
+    fragColor = vertColor;
+    gl_Position = projection * vec4(vert, 0.0, 1.0);
+}
+`
+
+// Evaluates the gradient at fragPos. Must match maxGradientStops and the
+// GradientKind constants.
+const gradientFunc = `
+#define MAX_STOPS 16
+#define PI 3.14159265358979
+
+in vec2 fragPos;
+
+uniform int kind;
+uniform vec2 start;
+uniform vec2 end;
+uniform int stopCount;
+uniform float offsets[MAX_STOPS];
+uniform vec4 colors[MAX_STOPS];
+
+vec4 gradient() {
+    vec2 d = end - start;
+    float t;
+    if (kind != 2 && dot(d, d) == 0) {
+        // Degenerate gradient, filled with the last stop.
+        t = 1;
+    } else if (kind == 0) {
+        t = dot(fragPos - start, d) / dot(d, d);
+    } else if (kind == 1) {
+        t = distance(fragPos, start) / length(d);
+    } else {
+        vec2 p = fragPos - start;
+        t = fract((atan(p.y, p.x) - atan(d.y, d.x)) / (2*PI));
+    }
+    t = clamp(t, 0, 1);
+
+    vec4 c = colors[0];
+    for (int i = 1; i < stopCount; i++) {
+        float w = max(offsets[i] - offsets[i-1], 1e-6);
+        c = mix(c, colors[i], clamp((t - offsets[i-1]) / w, 0, 1));
+    }
+    return c;
+}
+`
+
+const gradientFrag = `
+#version 330
+in vec4 fragColor;
+out vec4 frag_color;
+` + gradientFunc + `
+void main() {
+    frag_color = gradient() * fragColor;
+}
+`
+
+const fontGradientFrag = `
+#version 330
+in vec2 fragTexCoord;
+out vec4 frag_color;
+
+uniform sampler2D tex;
+` + gradientFunc + `
 void main() {
-   // convert the rectangle from pixels to 0.0 to 1.0
-   vec2 zeroToOne = vert / resolution;
-   // convert from 0->1 to 0->2
-   vec2 zeroToTwo = zeroToOne * 2.0;
-   // convert from 0->2 to -1->+1 (clipspace)
-   vec2 clipSpace = zeroToTwo - 1.0;
-   fragTexCoord = vertTexCoord;
-   gl_Position = vec4(clipSpace * vec2(1, -1), 0, 1);
+    vec4 c = gradient();
+    float a = texture(tex, vec2(fragTexCoord.x, 1-fragTexCoord.y)).r;
+    frag_color = vec4(c.rgb, c.a * a);
 }
 `
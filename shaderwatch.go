@@ -0,0 +1,238 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"io/fs"
+	"time"
+)
+
+// Default interval between checks of the shader sources.
+const DefaultShaderPollInterval = 500 * time.Millisecond
+
+// ShaderWatcher loads shaders from a file system and recompiles them when
+// their sources change. Intended for development: sources are polled for
+// modification time changes and reloaded shaders are swapped in place at the
+// next frame boundary (call to Clear), so existing *Shader and Material values
+// pick up the new program. If recompilation fails the last good program stays
+// in use and the error is passed to OnError.
+type ShaderWatcher struct {
+	PollInterval time.Duration
+	OnError      func(error)
+
+	fsys     fs.FS
+	shaders  []*watchedShader
+	lastPoll time.Time
+}
+
+type watchedShader struct {
+	shader *Shader
+
+	// Paths of the sources in the file system. Empty path means the source
+	// is fixed and never reloaded.
+	paths    [2]string
+	sources  [2]string
+	modTimes [2]time.Time
+}
+
+// Active watchers polled by Clear.
+var shaderWatchers []*ShaderWatcher
+
+// Creates new ShaderWatcher reading sources from fsys, for example
+// os.DirFS("shaders"). The watcher is polled on every frame until Close.
+func NewShaderWatcher(fsys fs.FS, onError func(error)) *ShaderWatcher {
+	w := &ShaderWatcher{
+		PollInterval: DefaultShaderPollInterval,
+		OnError:      onError,
+		fsys:         fsys,
+	}
+
+	shaderWatchers = append(shaderWatchers, w)
+	return w
+}
+
+// Compiles the shader from vertex and fragment source files and starts
+// watching them for changes.
+func (w *ShaderWatcher) Load(vertexPath, fragmentPath string) (*Shader, error) {
+	ws := &watchedShader{paths: [2]string{vertexPath, fragmentPath}}
+	if err := w.read(ws); err != nil {
+		return nil, err
+	}
+
+	shader, err := NewShader(ws.sources[0], ws.sources[1])
+	if err != nil {
+		return nil, err
+	}
+
+	ws.shader = shader
+	w.shaders = append(w.shaders, ws)
+	return shader, nil
+}
+
+// Replaces sources of the built-in shaders with files found in the file
+// system and watches them. Recognized file names are vertex.vert,
+// texture.vert, gradient.vert, instance.vert, polygon.frag, circle.frag,
+// texture.frag, font.frag, gradient.frag, font_gradient.frag and
+// instance_texture.frag; built-in sources are used for missing files. Must be
+// called after Init.
+func (w *ShaderWatcher) WatchBuiltin() error {
+	for _, b := range builtinShaders() {
+		ws := &watchedShader{shader: *b.shader, sources: [2]string{b.vertex, b.fragment}}
+		for i, name := range [2]string{b.vertexFile, b.fragmentFile} {
+			if _, err := fs.Stat(w.fsys, name); err == nil {
+				ws.paths[i] = name
+			}
+		}
+
+		if ws.paths[0] == "" && ws.paths[1] == "" {
+			continue
+		}
+
+		if err := w.read(ws); err != nil {
+			return err
+		}
+		if err := ws.shader.recompile(ws.sources[0], ws.sources[1]); err != nil {
+			return err
+		}
+
+		w.shaders = append(w.shaders, ws)
+	}
+
+	return nil
+}
+
+// Reads sources of the shader from the file system, recording their
+// modification times.
+func (w *ShaderWatcher) read(ws *watchedShader) error {
+	for i, path := range ws.paths {
+		if path == "" {
+			continue
+		}
+
+		info, err := fs.Stat(w.fsys, path)
+		if err != nil {
+			return err
+		}
+
+		data, err := fs.ReadFile(w.fsys, path)
+		if err != nil {
+			return err
+		}
+
+		ws.sources[i] = string(data)
+		ws.modTimes[i] = info.ModTime()
+	}
+
+	return nil
+}
+
+// Checks the sources for changes and recompiles modified shaders. Called
+// automatically by Clear, at most once per PollInterval.
+func (w *ShaderWatcher) Poll() {
+	now := time.Now()
+	if now.Sub(w.lastPoll) < w.PollInterval {
+		return
+	}
+	w.lastPoll = now
+
+	for _, ws := range w.shaders {
+		if !w.modified(ws) {
+			continue
+		}
+
+		err := w.read(ws)
+		if err == nil {
+			err = ws.shader.recompile(ws.sources[0], ws.sources[1])
+		}
+
+		if err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+	}
+}
+
+func (w *ShaderWatcher) modified(ws *watchedShader) bool {
+	for i, path := range ws.paths {
+		if path == "" {
+			continue
+		}
+
+		// Files being saved may briefly disappear, try again on the next poll.
+		info, err := fs.Stat(w.fsys, path)
+		if err == nil && !info.ModTime().Equal(ws.modTimes[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Stops watching. Loaded shaders remain valid.
+func (w *ShaderWatcher) Close() {
+	for i, watcher := range shaderWatchers {
+		if watcher == w {
+			shaderWatchers = append(shaderWatchers[:i], shaderWatchers[i+1:]...)
+			break
+		}
+	}
+}
+
+// Compiles new program from the sources and, on success, replaces the
+// program of the shader with it. Uniform values set on the shader are kept.
+func (s *Shader) recompile(vertexSource, fragmentSource string) error {
+	shader, err := NewShader(vertexSource, fragmentSource)
+	if err != nil {
+		return err
+	}
+
+	old := *s
+	*s = *shader
+	old.res.release()
+
+	if s.HasUniform("tex") {
+		s.SetInt("tex", 0)
+	}
+	s.restoreUniforms(&old)
+
+	return nil
+}
+
+// Uploads values last set on the old program to the uniforms of the same name
+// and type. Values no longer fitting the uniform are dropped.
+func (s *Shader) restoreUniforms(old *Shader) {
+	for name, u := range old.uniforms {
+		if nu, ok := s.uniforms[name]; !ok || nu.xtype != u.xtype {
+			continue
+		}
+
+		if val, ok := old.floats[u.location]; ok {
+			switch u.xtype {
+			case gl.FLOAT_MAT2, gl.FLOAT_MAT3, gl.FLOAT_MAT4:
+				s.SetMatrix(name, val)
+			default:
+				s.SetFloat(name, val...)
+			}
+		}
+		if val, ok := old.ints[u.location]; ok {
+			s.setInts(s.uniforms[name], name, val)
+		}
+	}
+}
+
+type builtinShader struct {
+	shader                   **Shader
+	vertex, fragment         string
+	vertexFile, fragmentFile string
+}
+
+func builtinShaders() []builtinShader {
+	return []builtinShader{
+		{&polygonShader, vertexVert, polygonFrag, "vertex.vert", "polygon.frag"},
+		{&circleShader, vertexVert, circleFrag, "vertex.vert", "circle.frag"},
+		{&textureShader, textureVert, textureFrag, "texture.vert", "texture.frag"},
+		{&fontShader, textureVert, fontFrag, "texture.vert", "font.frag"},
+		{&gradientShader, gradientVert, gradientFrag, "gradient.vert", "gradient.frag"},
+		{&fontGradientShader, gradientVert, fontGradientFrag, "gradient.vert", "font_gradient.frag"},
+		{&instanceShader, instanceVert, polygonFrag, "instance.vert", "polygon.frag"},
+		{&instanceTextureShader, instanceVert, instanceTextureFrag, "instance.vert", "instance_texture.frag"},
+	}
+}
@@ -0,0 +1,193 @@
+package layergl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// SpriteSheet is a texture divided into frames.
+type SpriteSheet struct {
+	Texture *Texture
+	Frames  []SpriteFrame
+
+	// Named ranges of frames, as defined by Aseprite frame tags.
+	Tags []SpriteTag
+}
+
+type SpriteFrame struct {
+	Name   string
+	Region TextureRegion
+
+	// Display time of the frame, zero if not specified.
+	Duration time.Duration
+}
+
+// Inclusive range of frames. Direction is "forward", "reverse" or "pingpong".
+type SpriteTag struct {
+	Name      string
+	From, To  int
+	Direction string
+}
+
+// Slices the texture into a grid of frames of the given size, in rows from
+// the top left corner. Incomplete frames at the right and bottom edges are
+// skipped.
+func NewSpriteSheetGrid(texture *Texture, frameWidth, frameHeight int) *SpriteSheet {
+	sheet := &SpriteSheet{Texture: texture}
+	if frameWidth <= 0 || frameHeight <= 0 {
+		return sheet
+	}
+
+	w, h := texture.Size()
+	for y := 0; y+frameHeight <= h; y += frameHeight {
+		for x := 0; x+frameWidth <= w; x += frameWidth {
+			sheet.Frames = append(sheet.Frames, SpriteFrame{
+				Name:   fmt.Sprint(len(sheet.Frames)),
+				Region: TextureRegion{x, y, frameWidth, frameHeight},
+			})
+		}
+	}
+
+	return sheet
+}
+
+// JSON layout shared by TexturePacker and Aseprite exports.
+type spriteSheetJSON struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+type spriteFrameJSON struct {
+	Filename string `json:"filename"`
+	Frame    struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frame"`
+	Rotated  bool `json:"rotated"`
+	Duration int  `json:"duration"`
+}
+
+// Reads frame rectangles for the texture from JSON data exported by
+// TexturePacker or Aseprite, in either the hash or the array format. Frame
+// durations and tags are read from Aseprite files. Rotated frames are not
+// supported.
+func LoadSpriteSheet(r io.Reader, texture *Texture) (*SpriteSheet, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc spriteSheetJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("sprite sheet: %v", err)
+	}
+
+	frames, err := decodeSpriteFrames(doc.Frames)
+	if err != nil {
+		return nil, fmt.Errorf("sprite sheet: %v", err)
+	}
+
+	sheet := &SpriteSheet{Texture: texture}
+	for _, f := range frames {
+		if f.Rotated {
+			return nil, fmt.Errorf("sprite sheet: frame %q: rotated frames are not supported", f.Filename)
+		}
+
+		sheet.Frames = append(sheet.Frames, SpriteFrame{
+			Name:     f.Filename,
+			Region:   TextureRegion{f.Frame.X, f.Frame.Y, f.Frame.W, f.Frame.H},
+			Duration: time.Duration(f.Duration) * time.Millisecond,
+		})
+	}
+
+	for _, tag := range doc.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(sheet.Frames) || tag.From > tag.To {
+			return nil, fmt.Errorf("sprite sheet: tag %q: invalid frame range %d-%d", tag.Name, tag.From, tag.To)
+		}
+
+		sheet.Tags = append(sheet.Tags, SpriteTag{tag.Name, tag.From, tag.To, tag.Direction})
+	}
+
+	return sheet, nil
+}
+
+// Decodes frames given either as an array or as an object keyed by file
+// name. Order of the object keys is preserved, since frame tags refer to
+// frames by index.
+func decodeSpriteFrames(raw json.RawMessage) ([]spriteFrameJSON, error) {
+	var frames []spriteFrameJSON
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("missing frames")
+	}
+
+	if raw[0] == '[' {
+		err := json.Unmarshal(raw, &frames)
+		return frames, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var f spriteFrameJSON
+		if err := dec.Decode(&f); err != nil {
+			return nil, err
+		}
+		if f.Filename == "" {
+			f.Filename, _ = key.(string)
+		}
+
+		frames = append(frames, f)
+	}
+
+	return frames, nil
+}
+
+// Returns index of the frame with the name, or -1.
+func (s *SpriteSheet) FrameIndex(name string) int {
+	for i, f := range s.Frames {
+		if f.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Returns texture of the frame, displayed at the given size.
+func (s *SpriteSheet) Frame(i int, width, height float64) *Texture {
+	return s.Texture.SubTexture(s.Frames[i].Region, width, height)
+}
+
+// Returns tag with the name and true, or false if there is none.
+func (s *SpriteSheet) Tag(name string) (SpriteTag, bool) {
+	for _, tag := range s.Tags {
+		if tag.Name == name {
+			return tag, true
+		}
+	}
+
+	return SpriteTag{}, false
+}
@@ -0,0 +1,108 @@
+package layergl
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSpriteSheetGrid(t *testing.T) {
+	sheet := NewSpriteSheetGrid(&Texture{size: image.Point{25, 20}}, 10, 8)
+
+	want := []TextureRegion{{0, 0, 10, 8}, {10, 0, 10, 8}, {0, 8, 10, 8}, {10, 8, 10, 8}}
+	if len(sheet.Frames) != len(want) {
+		t.Fatalf("%d frames, want %d", len(sheet.Frames), len(want))
+	}
+	for i, f := range sheet.Frames {
+		if f.Region != want[i] || f.Name != string(rune('0'+i)) {
+			t.Errorf("frame %d: %q at %v, want %v", i, f.Name, f.Region, want[i])
+		}
+	}
+}
+
+func TestLoadSpriteSheet(t *testing.T) {
+	const ms = time.Millisecond
+
+	tests := []struct {
+		name   string
+		json   string
+		frames []SpriteFrame
+		tags   []SpriteTag
+	}{
+		{
+			name: "array",
+			json: `{"frames": [
+				{"filename": "run_0.png", "frame": {"x": 0, "y": 0, "w": 16, "h": 24}, "rotated": false},
+				{"filename": "run_1.png", "frame": {"x": 16, "y": 0, "w": 16, "h": 24}, "rotated": false}
+			], "meta": {"app": "https://www.codeandweb.com/texturepacker"}}`,
+			frames: []SpriteFrame{
+				{"run_0.png", TextureRegion{0, 0, 16, 24}, 0},
+				{"run_1.png", TextureRegion{16, 0, 16, 24}, 0},
+			},
+		},
+		{
+			// Keys are not sorted, frames keep the order of the file.
+			name: "hash",
+			json: `{"frames": {
+				"walk_b.png": {"frame": {"x": 8, "y": 0, "w": 8, "h": 8}, "rotated": false},
+				"walk_a.png": {"frame": {"x": 0, "y": 0, "w": 8, "h": 8}, "rotated": false},
+				"walk_c.png": {"frame": {"x": 0, "y": 8, "w": 8, "h": 8}}
+			}}`,
+			frames: []SpriteFrame{
+				{"walk_b.png", TextureRegion{8, 0, 8, 8}, 0},
+				{"walk_a.png", TextureRegion{0, 0, 8, 8}, 0},
+				{"walk_c.png", TextureRegion{0, 8, 8, 8}, 0},
+			},
+		},
+		{
+			name: "aseprite",
+			json: `{"frames": {
+				"hero 0.aseprite": {"frame": {"x": 0, "y": 0, "w": 32, "h": 32}, "duration": 100},
+				"hero 1.aseprite": {"frame": {"x": 32, "y": 0, "w": 32, "h": 32}, "duration": 150},
+				"hero 2.aseprite": {"frame": {"x": 64, "y": 0, "w": 32, "h": 32}, "duration": 100}
+			}, "meta": {"frameTags": [
+				{"name": "idle", "from": 0, "to": 1, "direction": "forward"},
+				{"name": "jump", "from": 1, "to": 2, "direction": "pingpong"}
+			]}}`,
+			frames: []SpriteFrame{
+				{"hero 0.aseprite", TextureRegion{0, 0, 32, 32}, 100 * ms},
+				{"hero 1.aseprite", TextureRegion{32, 0, 32, 32}, 150 * ms},
+				{"hero 2.aseprite", TextureRegion{64, 0, 32, 32}, 100 * ms},
+			},
+			tags: []SpriteTag{{"idle", 0, 1, "forward"}, {"jump", 1, 2, "pingpong"}},
+		},
+	}
+
+	for _, test := range tests {
+		sheet, err := LoadSpriteSheet(strings.NewReader(test.json), nil)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(sheet.Frames, test.frames) {
+			t.Errorf("%s: frames %v, want %v", test.name, sheet.Frames, test.frames)
+		}
+		if !reflect.DeepEqual(sheet.Tags, test.tags) {
+			t.Errorf("%s: tags %v, want %v", test.name, sheet.Tags, test.tags)
+		}
+	}
+}
+
+func TestLoadSpriteSheetErrors(t *testing.T) {
+	tests := []struct {
+		name, json string
+	}{
+		{"syntax", `{"frames": [}`},
+		{"missing frames", `{"meta": {}}`},
+		{"rotated", `{"frames": [{"filename": "a", "frame": {"w": 1, "h": 1}, "rotated": true}]}`},
+		{"tag range", `{"frames": [{"filename": "a", "frame": {"w": 1, "h": 1}}],
+			"meta": {"frameTags": [{"name": "t", "from": 0, "to": 1}]}}`},
+	}
+	for _, test := range tests {
+		if _, err := LoadSpriteSheet(strings.NewReader(test.json), nil); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+// Number of texture units tracked by glState. OpenGL 3.3 guarantees at least
+// 16 units for the fragment stage.
+const maxTextureUnits = 16
+
+// glState mirrors the bindings of the GL context so that redundant calls
+// can be skipped. All binds of programs, vertex arrays and textures in the
+// package must go through it.
+type glState struct {
+	program     uint32
+	vao         uint32
+	arrayBuffer uint32
+	activeUnit  uint32
+	textures    [maxTextureUnits]uint32
+
+	blend      BlendMode
+	blendValid bool
+}
+
+var state glState
+
+// Driver calls made by glState. They are variables so that tests can count
+// the calls reaching the driver without a GL context.
+var (
+	glUseProgram    = gl.UseProgram
+	glActiveTexture = gl.ActiveTexture
+	glBindTexture   = gl.BindTexture
+)
+
+// Forgets all bindings, forcing the next binds to reach the driver.
+func (s *glState) reset() {
+	*s = glState{}
+	glActiveTexture(gl.TEXTURE0)
+}
+
+func (s *glState) useProgram(program uint32) {
+	if s.program != program {
+		s.program = program
+		glUseProgram(program)
+		frameStats.ShaderSwitches++
+	}
+}
+
+func (s *glState) bindVertexArray(vao uint32) {
+	if s.vao != vao {
+		s.vao = vao
+		gl.BindVertexArray(vao)
+	}
+}
+
+func (s *glState) bindArrayBuffer(vbo uint32) {
+	if s.arrayBuffer != vbo {
+		s.arrayBuffer = vbo
+		gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	}
+}
+
+// Binds the texture to the unit and makes the unit active, so that the
+// following texture calls act on the texture even if it was already bound.
+func (s *glState) bindTexture(unit, texture uint32) {
+	if s.activeUnit != unit {
+		s.activeUnit = unit
+		glActiveTexture(gl.TEXTURE0 + unit)
+	}
+
+	if unit < maxTextureUnits && s.textures[unit] == texture {
+		return
+	}
+
+	if unit < maxTextureUnits {
+		s.textures[unit] = texture
+	}
+	glBindTexture(gl.TEXTURE_2D, texture)
+	frameStats.TextureBinds++
+}
+
+// Called when a program is deleted, since its name may be reused.
+func (s *glState) deleteProgram(program uint32) {
+	if s.program == program {
+		s.program = 0
+	}
+	gl.DeleteProgram(program)
+}
+
+// Sets blend function, enabling or disabling blending as needed.
+func (s *glState) setBlend(mode BlendMode) {
+	if s.blendValid && s.blend == mode {
+		return
+	}
+
+	replace := mode == BlendReplace
+	if !s.blendValid || replace != (s.blend == BlendReplace) {
+		if replace {
+			gl.Disable(gl.BLEND)
+		} else {
+			gl.Enable(gl.BLEND)
+		}
+	}
+
+	if !replace {
+		gl.BlendFuncSeparate(uint32(mode.SrcRGB), uint32(mode.DstRGB), uint32(mode.SrcAlpha), uint32(mode.DstAlpha))
+	}
+
+	s.blend = mode
+	s.blendValid = true
+}
+
+// Called when a texture is deleted, since its name may be reused.
+func (s *glState) deleteTexture(texture uint32) {
+	for unit := range s.textures {
+		if s.textures[unit] == texture {
+			s.textures[unit] = 0
+		}
+	}
+	gl.DeleteTextures(1, &texture)
+}
+
+// Called when a vertex array is deleted, since its name may be reused.
+func (s *glState) deleteVertexArray(vao uint32) {
+	if s.vao == vao {
+		s.vao = 0
+	}
+	gl.DeleteVertexArrays(1, &vao)
+}
+
+// Called when a buffer is deleted, since its name may be reused.
+func (s *glState) deleteBuffer(buffer uint32) {
+	if s.arrayBuffer == buffer {
+		s.arrayBuffer = 0
+	}
+	gl.DeleteBuffers(1, &buffer)
+}
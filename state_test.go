@@ -0,0 +1,126 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"testing"
+)
+
+// Driver call recorded by the stubs of countStateCalls.
+type stateCall struct {
+	name string
+	args [2]uint32
+}
+
+// Replaces the driver calls of glState with stubs recording them, so that
+// the state can be used without a GL context.
+func countStateCalls(tb testing.TB) *[]stateCall {
+	calls := new([]stateCall)
+	use, active, bind := glUseProgram, glActiveTexture, glBindTexture
+	glUseProgram = func(program uint32) {
+		*calls = append(*calls, stateCall{"UseProgram", [2]uint32{program}})
+	}
+	glActiveTexture = func(unit uint32) {
+		*calls = append(*calls, stateCall{"ActiveTexture", [2]uint32{unit}})
+	}
+	glBindTexture = func(target, texture uint32) {
+		*calls = append(*calls, stateCall{"BindTexture", [2]uint32{target, texture}})
+	}
+
+	saved := state
+	tb.Cleanup(func() {
+		glUseProgram, glActiveTexture, glBindTexture = use, active, bind
+		state = saved
+	})
+
+	return calls
+}
+
+func TestBindTextureActivatesUnit(t *testing.T) {
+	calls := countStateCalls(t)
+	state.reset()
+
+	state.bindTexture(1, 5)
+	state.bindTexture(0, 7)
+	*calls = nil
+
+	// Texture 5 is already bound to unit 1, but the unit must become active
+	// for the calls modifying the texture.
+	state.bindTexture(1, 5)
+	want := []stateCall{{"ActiveTexture", [2]uint32{gl.TEXTURE0 + 1}}}
+	if len(*calls) != len(want) || (*calls)[0] != want[0] {
+		t.Fatalf("calls = %v, want %v", *calls, want)
+	}
+
+	*calls = nil
+	state.bindTexture(1, 5)
+	if len(*calls) != 0 {
+		t.Fatalf("redundant bind made calls %v", *calls)
+	}
+}
+
+func TestUseProgramSkipsRedundant(t *testing.T) {
+	calls := countStateCalls(t)
+	state.reset()
+	*calls = nil
+
+	for _, program := range []uint32{1, 1, 2, 2, 1} {
+		state.useProgram(program)
+	}
+	if len(*calls) != 3 {
+		t.Fatalf("calls = %v, want 3 UseProgram calls", *calls)
+	}
+}
+
+// Shader usable without a GL context as long as only its uniform cache is
+// consulted.
+func newCacheShader(program uint32) *Shader {
+	return &Shader{
+		program: program,
+		floats:  make(map[int32][]float32),
+		ints:    make(map[int32][]int32),
+	}
+}
+
+// Simulates a frame of sprites and shapes drawn in batches sharing shaders,
+// textures and colors, and reports how many of the requested program binds,
+// texture binds and uniform uploads reach the driver.
+func BenchmarkStateCache(b *testing.B) {
+	const (
+		draws        = 200
+		batch        = 10
+		projection   = 0
+		colorUniform = 1
+	)
+
+	calls := countStateCalls(b)
+	shaders := []*Shader{newCacheShader(1), newCacheShader(2)}
+	textures := []uint32{10, 11, 12, 13}
+	matrix := orthoProjection(0, 640, 0, 480, -1, 1)
+	colors := [][]float32{{1, 1, 1, 1}, {1, 0, 0, 1}}
+
+	var requested, uniforms int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state.reset()
+
+		for d := 0; d < draws; d++ {
+			s := shaders[d/(batch*5)%len(shaders)]
+
+			s.bind()
+			state.bindTexture(0, textures[d/batch%len(textures)])
+			if s.updateFloats(projection, matrix) {
+				uniforms++
+			}
+			if s.updateFloats(colorUniform, colors[d/(batch*2)%len(colors)]) {
+				uniforms++
+			}
+			requested += 4
+		}
+	}
+	b.StopTimer()
+
+	issued := len(*calls) + uniforms
+	b.ReportMetric(float64(requested)/float64(b.N), "requested/frame")
+	b.ReportMetric(float64(issued)/float64(b.N), "issued/frame")
+	b.ReportMetric(float64(requested-issued)/float64(b.N), "skipped/frame")
+}
@@ -0,0 +1,60 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"time"
+)
+
+// FrameStats counts the rendering work of one frame, from one Clear to the
+// next.
+type FrameStats struct {
+	DrawCalls int
+
+	// Vertices processed by the draw calls, counting every instance.
+	Vertices  int
+	Triangles int
+
+	// Binds and switches that reached the driver, redundant ones are skipped
+	// and not counted.
+	TextureBinds   int
+	ShaderSwitches int
+
+	// Dynamic buffers grown to fit the uploaded data.
+	Reallocations int
+
+	// Time from the start of the frame to the start of the next one.
+	FrameTime time.Duration
+}
+
+var (
+	// Counters of the frame in progress and of the last complete frame.
+	frameStats, lastFrameStats FrameStats
+
+	frameStart time.Time
+)
+
+// Returns statistics of the last complete frame.
+func Stats() FrameStats {
+	return lastFrameStats
+}
+
+// Finishes counting of the frame in progress and starts the next one.
+func nextFrameStats() {
+	now := time.Now()
+	if !frameStart.IsZero() {
+		frameStats.FrameTime = now.Sub(frameStart)
+		lastFrameStats = frameStats
+	}
+
+	frameStats = FrameStats{}
+	frameStart = now
+}
+
+// Counts draw call of elements vertices in the primitive mode.
+func countDraw(mode uint32, elements, instances int) {
+	frameStats.DrawCalls++
+	frameStats.Vertices += elements * instances
+	if mode == gl.TRIANGLES {
+		frameStats.Triangles += elements / 3 * instances
+	}
+}
@@ -0,0 +1,49 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"testing"
+	"time"
+)
+
+func TestFrameStats(t *testing.T) {
+	defer func() {
+		frameStats, lastFrameStats, frameStart = FrameStats{}, FrameStats{}, time.Time{}
+	}()
+	frameStats, lastFrameStats, frameStart = FrameStats{}, FrameStats{}, time.Time{}
+
+	// The first frame is not complete, nothing is reported.
+	countDraw(gl.TRIANGLES, 3, 1)
+	nextFrameStats()
+	if s := Stats(); s != (FrameStats{}) {
+		t.Fatalf("stats before the first frame: %+v", s)
+	}
+
+	countDraw(gl.TRIANGLES, 6, 1)
+	countDraw(gl.TRIANGLES, 6, 10)
+	countDraw(gl.LINE_STRIP, 5, 1)
+	countDraw(gl.LINES, 4, 3)
+	if s := Stats(); s != (FrameStats{}) {
+		t.Fatalf("stats of the frame in progress reported: %+v", s)
+	}
+
+	time.Sleep(time.Millisecond)
+	nextFrameStats()
+	s := Stats()
+	if s.FrameTime < time.Millisecond {
+		t.Errorf("frame time %v, want at least 1ms", s.FrameTime)
+	}
+	s.FrameTime = 0
+	if want := (FrameStats{DrawCalls: 4, Vertices: 6 + 60 + 5 + 12, Triangles: 2 + 20}); s != want {
+		t.Errorf("stats %+v, want %+v", s, want)
+	}
+
+	// Counting starts over in every frame.
+	countDraw(gl.TRIANGLES, 3, 1)
+	nextFrameStats()
+	s = Stats()
+	s.FrameTime = 0
+	if want := (FrameStats{DrawCalls: 1, Vertices: 3, Triangles: 1}); s != want {
+		t.Errorf("stats of the next frame %+v, want %+v", s, want)
+	}
+}
@@ -0,0 +1,187 @@
+package layergl
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	// Number of frames shown in the graph of the overlay.
+	statsHistory = 120
+
+	statsBarWidth    = 2
+	statsGraphHeight = 60
+	statsPadding     = 6
+	statsFontSize    = 14
+
+	// Frame time at the top of the graph.
+	statsGraphMax = 50 * time.Millisecond
+)
+
+// StatsOverlay draws a graph of the recent frame times and the counters of
+// the last frame, as returned by Stats. Its own draw calls are counted in
+// the statistics of the frame it is drawn in.
+type StatsOverlay struct {
+	// Bottom left corner of the overlay, in window coordinates.
+	Position Point
+
+	// Font of the counters, the built-in font if nil.
+	Font *Font
+
+	times   [statsHistory]time.Duration
+	next    int
+	count   int
+	ownFont bool
+
+	// Loading of the built-in font failed and was reported.
+	fontFailed bool
+}
+
+// Creates StatsOverlay at the position.
+func NewStatsOverlay(position Point) *StatsOverlay {
+	return &StatsOverlay{Position: position}
+}
+
+// Records the last frame and draws the overlay above everything drawn
+// before, ignoring the current transformation. It is called once per frame.
+func (o *StatsOverlay) Draw() {
+	stats := Stats()
+	if stats.FrameTime > 0 {
+		o.times[o.next] = stats.FrameTime
+		o.next = (o.next + 1) % statsHistory
+		if o.count < statsHistory {
+			o.count++
+		}
+	}
+
+	// Loading is retried every frame until it succeeds, the error is logged
+	// once.
+	if o.Font == nil && !o.ownFont {
+		font, err := DefaultFont(statsFontSize)
+		if err != nil && !o.fontFailed {
+			log.Println("StatsOverlay: unable to load font:", err)
+			o.fontFailed = true
+		}
+		if err == nil {
+			o.Font, o.ownFont = font, true
+		}
+	}
+
+	lines := []string{
+		"%.0f FPS  %.1f ms",
+		"draw calls %d",
+		"vertices %d",
+		"triangles %d",
+		"texture binds %d",
+		"shader switches %d",
+		"reallocations %d",
+	}
+	values := [][]interface{}{
+		{o.fps(), float64(stats.FrameTime) / float64(time.Millisecond)},
+		{stats.DrawCalls},
+		{stats.Vertices},
+		{stats.Triangles},
+		{stats.TextureBinds},
+		{stats.ShaderSwitches},
+		{stats.Reallocations},
+	}
+
+	var lineHeight float64
+	if o.Font != nil {
+		lineHeight = o.Font.Ascent(1) + o.Font.Descent(1)
+	}
+
+	width := float64(statsHistory*statsBarWidth + 2*statsPadding)
+	height := statsGraphHeight + lineHeight*float64(len(lines)) + 3*statsPadding
+
+	PushTransform(CurrentTransform().Invert())
+	defer PopTransform()
+
+	x, y := o.Position.X, o.Position.Y
+	DrawRect(Rect{x, y, x + width, y + height}, Color{0, 0, 0, 0.6})
+
+	graph := Rect{x + statsPadding, y + statsPadding, x + width - statsPadding, y + statsPadding + statsGraphHeight}
+	o.drawGraph(graph)
+
+	if o.Font == nil {
+		return
+	}
+
+	baseline := graph.Y2 + statsPadding + o.Font.Descent(1)
+	for i := len(lines) - 1; i >= 0; i-- {
+		o.Font.Printf(Point{graph.X1, baseline}, Color{1, 1, 1, 1}, 1, lines[i], values[i]...)
+		baseline += lineHeight
+	}
+}
+
+// Draws bars of the recorded frame times, the oldest on the left, and the
+// lines of 60 and 30 frames per second.
+func (o *StatsOverlay) drawGraph(r Rect) {
+	if o.count > 0 {
+		bars := new(VertexObject)
+		for i := 0; i < o.count; i++ {
+			t := o.times[(o.next-o.count+i+statsHistory)%statsHistory]
+
+			h := float64(t) / float64(statsGraphMax)
+			if h > 1 {
+				h = 1
+			}
+
+			x := r.X2 - float64((o.count-i)*statsBarWidth)
+			bar := Rectangle(Rect{x, r.Y1, x + statsBarWidth, r.Y1 + h*r.Height()})
+
+			n := len(bars.Vertices)
+			for _, index := range bar.Indices {
+				bars.Indices = append(bars.Indices, n+index)
+			}
+			bars.Vertices = append(bars.Vertices, bar.Vertices...)
+
+			color := frameTimeColor(t)
+			for range bar.Vertices {
+				bars.Colors = append(bars.Colors, color)
+			}
+		}
+
+		DrawVertexObject(bars, Color{1, 1, 1, 1})
+	}
+
+	for _, t := range []time.Duration{time.Second / 60, time.Second / 30} {
+		y := r.Y1 + float64(t)/float64(statsGraphMax)*r.Height()
+		DrawLines([]Point{{r.X1, y}, {r.X2, y}}, Color{1, 1, 1, 0.4})
+	}
+}
+
+// Returns color of the frame time in the graph: green up to 60 frames per
+// second, yellow up to 30 and red below.
+func frameTimeColor(t time.Duration) Color {
+	switch {
+	case t <= time.Second/60+time.Millisecond:
+		return Color{0.3, 0.9, 0.3, 1}
+	case t <= time.Second/30+time.Millisecond:
+		return Color{0.9, 0.8, 0.2, 1}
+	default:
+		return Color{0.9, 0.3, 0.3, 1}
+	}
+}
+
+// Returns average frame rate of the recorded frames.
+func (o *StatsOverlay) fps() float64 {
+	var total time.Duration
+	for i := 0; i < o.count; i++ {
+		total += o.times[i]
+	}
+	if total == 0 {
+		return 0
+	}
+
+	return float64(o.count) / total.Seconds()
+}
+
+// Deletes the font loaded by the overlay.
+func (o *StatsOverlay) Delete() {
+	if o.ownFont && o.Font != nil {
+		o.Font.Delete()
+		o.Font = nil
+	}
+	o.ownFont = false
+}
@@ -7,6 +7,7 @@ import (
 	"image/draw"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"os"
 )
 
@@ -14,54 +15,312 @@ type Texture struct {
 	*VertexObject
 	width, height float32
 	tex           uint32
+
+	// Size of the image in pixels.
+	size image.Point
+
+	options TextureOptions
+
+	// Shared by all textures using the same GL texture.
+	res *resource
+
+	// Asset path of the image, used to refer to it when saving scenes. Set by
+	// NewTexture and by scene loading.
+	Path string
+
+	// Part of the image drawn on the quad. Zero region draws the whole image.
+	Region TextureRegion
+
+	// Mirror the region horizontally or vertically.
+	FlipX, FlipY bool
+
+	// Offset of the texture coordinates in multiples of the region size, used
+	// to scroll the image across the quad.
+	Scroll Point
+
+	// Number of times the region is tiled across the quad in each direction.
+	// Zero means once. The whole image is tiled by the sampler according to
+	// WrapS and WrapT of its options, falling back to plain repetition for
+	// WrapClampToEdge; atlas regions are always repeated plainly.
+	Repeat Point
+}
+
+// TextureRegion is a rectangle of a texture in pixels, with the origin at the
+// top left corner of the image.
+type TextureRegion struct {
+	X, Y          int
+	Width, Height int
+}
+
+func (r TextureRegion) empty() bool {
+	return r.Width <= 0 || r.Height <= 0
 }
 
-func loadImage(fileName string) (uint32, error) {
+func loadImage(fileName string, options TextureOptions) (uint32, image.Point, error) {
 	imgFile, err := os.Open(fileName)
 	if err != nil {
-		return 0, err
+		return 0, image.Point{}, err
 	}
 	defer imgFile.Close()
 
 	img, _, err := image.Decode(imgFile)
 	if err != nil {
-		return 0, err
+		return 0, image.Point{}, err
 	}
 
+	return uploadImage(img, options)
+}
+
+// Creates GL texture from the image.
+func uploadImage(img image.Image, options TextureOptions) (uint32, image.Point, error) {
 	rgba := image.NewRGBA(img.Bounds())
 	if rgba.Stride != rgba.Rect.Size().X*4 {
-		return 0, fmt.Errorf("unsupported stride")
+		return 0, image.Point{}, fmt.Errorf("unsupported stride")
 	}
 
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
 
 	var texture uint32
 	gl.GenTextures(1, &texture)
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	state.bindTexture(0, texture)
 	gl.TexImage2D(
-		gl.TEXTURE_2D, 0, gl.RGBA,
+		gl.TEXTURE_2D, 0, options.internalFormat(),
 		int32(rgba.Rect.Size().X), int32(rgba.Rect.Size().Y), 0,
 		gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+	options.apply()
 
-	return texture, nil
+	return texture, rgba.Rect.Size(), nil
 }
 
-// Loads and creates new Texture object.
-func NewTexture(fileName string, width, height float64) (texture *Texture, err error) {
+// Loads and creates new Texture object. Sampling options are optional, by
+// default textures are filtered linearly and clamped to the edge.
+func NewTexture(fileName string, width, height float64, options ...TextureOptions) (texture *Texture, err error) {
 	texture = new(Texture)
 	texture.VertexObject = Rectangle(Rect{0, 0, width, height})
-	texture.tex, err = loadImage(fileName)
+	texture.options = textureOptions(options)
+	texture.tex, texture.size, err = loadImage(fileName, texture.options)
 	texture.width = float32(width)
 	texture.height = float32(height)
+	if err == nil {
+		texture.res = trackTexture(fileName, texture.tex)
+		texture.Path = fileName
+	}
 	return
 }
 
+// Creates new Texture from the image, displayed at the size of the image.
+func NewTextureFromImage(img image.Image, options ...TextureOptions) (*Texture, error) {
+	opts := textureOptions(options)
+	tex, size, err := uploadImage(img, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	texture := new(Texture)
+	texture.VertexObject = Rectangle(Rect{0, 0, float64(size.X), float64(size.Y)})
+	texture.tex = tex
+	texture.size = size
+	texture.options = opts
+	texture.width = float32(size.X)
+	texture.height = float32(size.Y)
+	texture.res = trackTexture("", tex)
+	return texture, nil
+}
+
+func trackTexture(name string, tex uint32) *resource {
+	return trackResource("texture", name, func() { state.deleteTexture(tex) })
+}
+
+// Deletes the GL texture. Textures sharing it, such as sub-textures and
+// atlas regions, must not be drawn afterwards.
+func (t *Texture) Delete() {
+	t.res.release()
+	t.tex = 0
+}
+
+// Creates new Texture from RGBA pixels laid out as in image.RGBA, 4 bytes per
+// pixel, rows from top to bottom.
+func NewTextureFromRGBA(pix []byte, width, height int, options ...TextureOptions) (*Texture, error) {
+	if width <= 0 || height <= 0 || len(pix) != width*height*4 {
+		return nil, fmt.Errorf("NewTextureFromRGBA: %d bytes do not match %dx%d pixels", len(pix), width, height)
+	}
+
+	img := &image.RGBA{Pix: pix, Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+	return NewTextureFromImage(img, options...)
+}
+
+// Decodes image in any registered format (PNG and JPEG by default) and creates
+// new Texture from it.
+func NewTextureFromReader(r io.Reader, options ...TextureOptions) (*Texture, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTextureFromImage(img, options...)
+}
+
+// Replaces pixels of the rectangle with RGBA pixels laid out as in image.RGBA.
+// The rectangle is relative to the texture region, if set. Intended for
+// streaming, such as video frames.
+func (t *Texture) Update(rect image.Rectangle, pix []byte) error {
+	if len(pix) != rect.Dx()*rect.Dy()*4 {
+		return fmt.Errorf("Texture.Update: %d bytes do not match %dx%d pixels", len(pix), rect.Dx(), rect.Dy())
+	}
+
+	bounds := t.pixelBounds()
+	rect = rect.Add(bounds.Min)
+	if !rect.In(bounds) {
+		return fmt.Errorf("Texture.Update: %v is outside of the texture", rect.Sub(bounds.Min))
+	}
+	if rect.Empty() {
+		return nil
+	}
+
+	state.bindTexture(0, t.tex)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(rect.Min.X), int32(rect.Min.Y),
+		int32(rect.Dx()), int32(rect.Dy()), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pix))
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+
+	if t.options.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+
+	return nil
+}
+
+// Reads the pixels of the texture, or of its region, back from the GPU.
+func (t *Texture) Pixels() *image.RGBA {
+	full := image.NewRGBA(image.Rect(0, 0, t.size.X, t.size.Y))
+	if len(full.Pix) == 0 {
+		return full
+	}
+
+	state.bindTexture(0, t.tex)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.GetTexImage(gl.TEXTURE_2D, 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(full.Pix))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 4)
+
+	bounds := t.pixelBounds()
+	if bounds == full.Rect {
+		return full
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, full, bounds.Min, draw.Src)
+	return rgba
+}
+
+// Returns the region of the texture in pixels, or the whole image.
+func (t *Texture) pixelBounds() image.Rectangle {
+	if t.Region.empty() {
+		return image.Rect(0, 0, t.size.X, t.size.Y)
+	}
+
+	r := t.Region
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
+}
+
+// Returns new Texture of the given size drawing the region of the image.
+// The GL texture is shared with t.
+func (t *Texture) SubTexture(region TextureRegion, width, height float64) *Texture {
+	return &Texture{
+		VertexObject: Rectangle(Rect{0, 0, width, height}),
+		width:        float32(width),
+		height:       float32(height),
+		tex:          t.tex,
+		size:         t.size,
+		options:      t.options,
+		res:          t.res,
+		Path:         t.Path,
+		Region:       region,
+	}
+}
+
+// Returns size of the image in pixels.
+func (t *Texture) Size() (width, height int) {
+	return t.size.X, t.size.Y
+}
+
+// Returns texture coordinates of the quad vertices, see Rectangle.
+func (t *Texture) uvs() []float32 {
+	rx, ry := t.Repeat.X, t.Repeat.Y
+	if rx == 0 {
+		rx = 1
+	}
+	if ry == 0 {
+		ry = 1
+	}
+
+	u1, u2 := t.Scroll.X, t.Scroll.X+rx
+	v1, v2 := t.Scroll.Y, t.Scroll.Y+ry
+	if t.FlipX {
+		u1, u2 = u2, u1
+	}
+	if t.FlipY {
+		v1, v2 = v2, v1
+	}
+
+	return []float32{
+		float32(u1), float32(v1),
+		float32(u1), float32(v2),
+		float32(u2), float32(v1),
+		float32(u2), float32(v2),
+	}
+}
+
+// Returns true if the texture coordinates leave the region and must be wrapped
+// by the shader. The whole image is wrapped by the sampler instead, so that
+// WrapRepeat and WrapMirroredRepeat apply, unless the coordinates leave it
+// along an axis clamped to the edge.
+func (t *Texture) wraps() bool {
+	wrapX := (t.Repeat.X != 0 && t.Repeat.X != 1) || t.Scroll.X != 0
+	wrapY := (t.Repeat.Y != 0 && t.Repeat.Y != 1) || t.Scroll.Y != 0
+	if !wrapX && !wrapY {
+		return false
+	}
+	if !t.wholeImage() {
+		return true
+	}
+
+	return (wrapX && t.options.WrapS == WrapClampToEdge) || (wrapY && t.options.WrapT == WrapClampToEdge)
+}
+
+// Returns true if the region covers the whole image.
+func (t *Texture) wholeImage() bool {
+	r := t.Region
+	return r.empty() || (r.X == 0 && r.Y == 0 && r.Width == t.size.X && r.Height == t.size.Y)
+}
+
+// Returns the region in normalized texture coordinates: left, top, right, bottom.
+func (t *Texture) regionUniform() []float32 {
+	if t.Region.empty() || t.size.X == 0 || t.size.Y == 0 {
+		return []float32{0, 0, 1, 1}
+	}
+
+	w, h := float32(t.size.X), float32(t.size.Y)
+	r := t.Region
+	return []float32{
+		float32(r.X) / w, float32(r.Y) / h,
+		float32(r.X+r.Width) / w, float32(r.Y+r.Height) / h,
+	}
+}
+
+// Sets uniforms of the texture shader describing the region.
+func (t *Texture) applyRegion(s *Shader) {
+	if s.HasUniform("region") {
+		s.SetFloat("region", t.regionUniform()...)
+	}
+	if s.HasUniform("wrap") {
+		s.SetBool("wrap", t.wraps())
+	}
+}
+
 func (t *Texture) bind() {
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D, t.tex)
+	t.bindUnit(0)
+}
+
+func (t *Texture) bindUnit(unit uint32) {
+	state.bindTexture(unit, t.tex)
 }
@@ -0,0 +1,38 @@
+package layergl
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTextureWraps(t *testing.T) {
+	repeat := TextureOptions{WrapS: WrapRepeat, WrapT: WrapMirroredRepeat}
+	region := TextureRegion{X: 16, Width: 16, Height: 16}
+	whole := TextureRegion{Width: 64, Height: 32}
+
+	tests := []struct {
+		name    string
+		options TextureOptions
+		region  TextureRegion
+		scroll  Point
+		repeat  Point
+		want    bool
+	}{
+		{"drawn once", TextureOptions{}, TextureRegion{}, Point{}, Point{1, 0}, false},
+		{"clamped image", TextureOptions{}, TextureRegion{}, Point{}, Point{2, 2}, true},
+		{"repeating image", repeat, TextureRegion{}, Point{}, Point{2, 3}, false},
+		{"scrolled repeating image", repeat, TextureRegion{}, Point{0.5, 0.5}, Point{}, false},
+		{"region covering image", repeat, whole, Point{}, Point{2, 2}, false},
+		{"clamped axis", TextureOptions{WrapS: WrapRepeat}, TextureRegion{}, Point{}, Point{2, 2}, true},
+		{"other axis clamped", TextureOptions{WrapS: WrapRepeat}, TextureRegion{}, Point{0.5, 0}, Point{3, 1}, false},
+		{"atlas region", repeat, region, Point{}, Point{2, 2}, true},
+		{"scrolled atlas region", repeat, region, Point{0, 0.25}, Point{}, true},
+	}
+
+	for _, test := range tests {
+		tex := &Texture{size: image.Point{64, 32}, options: test.options, Region: test.region, Scroll: test.scroll, Repeat: test.repeat}
+		if got := tex.wraps(); got != test.want {
+			t.Errorf("%s: wraps %v, want %v", test.name, got, test.want)
+		}
+	}
+}
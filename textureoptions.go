@@ -0,0 +1,157 @@
+package layergl
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"strings"
+)
+
+type TextureFilter int
+
+const (
+	FilterLinear TextureFilter = iota
+	FilterNearest
+)
+
+type TextureWrap int
+
+const (
+	WrapClampToEdge TextureWrap = iota
+	WrapRepeat
+	WrapMirroredRepeat
+)
+
+// TextureOptions control how a texture is sampled. The zero value filters
+// linearly and clamps to the edge, without mipmaps.
+type TextureOptions struct {
+	MinFilter, MagFilter TextureFilter
+	WrapS, WrapT         TextureWrap
+
+	// Generate mipmaps and use them when the texture is scaled down. Linear
+	// MinFilter then gives trilinear filtering.
+	Mipmaps bool
+
+	// Maximum degree of anisotropic filtering, 0 or 1 disables it. Clamped to
+	// the maximum supported by the driver and ignored if the anisotropic
+	// filtering extension is not available.
+	Anisotropy float32
+
+	// Store the texture as sRGB, so that it is converted to linear colors
+	// when sampled.
+	SRGB bool
+}
+
+// From EXT_texture_filter_anisotropic, core since OpenGL 4.6.
+const (
+	textureMaxAnisotropy    = 0x84FE
+	maxTextureMaxAnisotropy = 0x84FF
+)
+
+// Maximum anisotropy supported by the driver, 0 if unsupported. Set by Init.
+var maxAnisotropy float32
+
+// Queries support of the optional texture features.
+func initTextureFeatures() {
+	maxAnisotropy = 0
+
+	var n int32
+	gl.GetIntegerv(gl.NUM_EXTENSIONS, &n)
+	for i := int32(0); i < n; i++ {
+		ext := gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))
+		if strings.HasSuffix(ext, "_texture_filter_anisotropic") {
+			gl.GetFloatv(maxTextureMaxAnisotropy, &maxAnisotropy)
+			break
+		}
+	}
+}
+
+func textureOptions(options []TextureOptions) TextureOptions {
+	if len(options) == 0 {
+		return TextureOptions{}
+	}
+
+	return options[0]
+}
+
+func (o TextureOptions) internalFormat() int32 {
+	if o.SRGB {
+		return gl.SRGB8_ALPHA8
+	}
+
+	return gl.RGBA
+}
+
+// Sets the sampling parameters of the texture bound to TEXTURE_2D.
+func (o TextureOptions) apply() {
+	minFilter := int32(gl.LINEAR)
+	switch {
+	case o.Mipmaps && o.MinFilter == FilterNearest:
+		minFilter = gl.NEAREST_MIPMAP_NEAREST
+	case o.Mipmaps:
+		minFilter = gl.LINEAR_MIPMAP_LINEAR
+	case o.MinFilter == FilterNearest:
+		minFilter = gl.NEAREST
+	}
+
+	magFilter := int32(gl.LINEAR)
+	if o.MagFilter == FilterNearest {
+		magFilter = gl.NEAREST
+	}
+
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, minFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, magFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, o.WrapS.param())
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, o.WrapT.param())
+
+	if maxAnisotropy > 0 {
+		anisotropy := o.Anisotropy
+		if anisotropy < 1 {
+			anisotropy = 1
+		}
+		if anisotropy > maxAnisotropy {
+			anisotropy = maxAnisotropy
+		}
+		gl.TexParameterf(gl.TEXTURE_2D, textureMaxAnisotropy, anisotropy)
+	}
+
+	if o.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+}
+
+func (w TextureWrap) param() int32 {
+	switch w {
+	case WrapRepeat:
+		return gl.REPEAT
+	case WrapMirroredRepeat:
+		return gl.MIRRORED_REPEAT
+	}
+
+	return gl.CLAMP_TO_EDGE
+}
+
+// Returns the sampling options of the texture.
+func (t *Texture) Options() TextureOptions {
+	return t.options
+}
+
+// Changes the sampling options of the texture. Textures sharing the image,
+// such as sub-textures and atlas regions, are affected as well. Changing SRGB
+// uploads the image again.
+func (t *Texture) SetOptions(options TextureOptions) {
+	if options.SRGB != t.options.SRGB && t.size.X > 0 && t.size.Y > 0 {
+		region := t.Region
+		t.Region = TextureRegion{}
+		pixels := t.Pixels()
+		t.Region = region
+
+		state.bindTexture(0, t.tex)
+		gl.TexImage2D(
+			gl.TEXTURE_2D, 0, options.internalFormat(),
+			int32(t.size.X), int32(t.size.Y), 0,
+			gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels.Pix))
+	}
+
+	t.options = options
+	state.bindTexture(0, t.tex)
+	options.apply()
+}
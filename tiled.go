@@ -0,0 +1,688 @@
+package layergl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Map read from a Tiled file, before the tilesets are loaded. Group layers are
+// flattened, their offsets, visibility and opacity applied to the children.
+type tiledMap struct {
+	orientation           string
+	width, height         int
+	tileWidth, tileHeight int
+	tilesets              []tiledTileset
+	layers                []tiledLayer
+}
+
+type tiledTileset struct {
+	firstGID uint32
+	source   string
+
+	name                  string
+	tileWidth, tileHeight int
+	spacing, margin       int
+	columns, tileCount    int
+	image                 string
+	animations            map[int][]TileFrame
+}
+
+type tiledLayer struct {
+	objects bool
+	name    string
+	hidden  bool
+	opacity float64
+	offset  Point
+
+	chunks []tiledChunk
+	objs   []tiledObject
+}
+
+type tiledChunk struct {
+	x, y, width, height int
+	gids                []uint32
+}
+
+type tiledObject struct {
+	id                  int
+	name, typ           string
+	x, y, width, height float64
+	rotation            float64
+	gid                 uint32
+	ellipse, point      bool
+	polygon, polyline   []Point
+	hidden              bool
+	properties          map[string]string
+}
+
+// Reads Tiled map in the XML (.tmx) or JSON (.tmj) format, detected by the
+// content. External tilesets are read from fsys relative to the map.
+func readTiledMap(fsys fs.FS, name string) (*tiledMap, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *tiledMap
+	if isXML(data) {
+		m, err = parseTMX(data)
+	} else {
+		m, err = parseTMJ(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	dir := path.Dir(name)
+	for i, ts := range m.tilesets {
+		if ts.source == "" {
+			m.tilesets[i].image = resolvePath(dir, ts.image)
+			continue
+		}
+
+		source := resolvePath(dir, ts.source)
+		data, err := fs.ReadFile(fsys, source)
+		if err != nil {
+			return nil, err
+		}
+
+		var ext tiledTileset
+		if isXML(data) {
+			var t tmxTileset
+			err = xml.Unmarshal(data, &t)
+			ext = t.tileset()
+		} else {
+			var t tmjTileset
+			err = json.Unmarshal(data, &t)
+			ext = t.tileset()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", source, err)
+		}
+
+		ext.firstGID = ts.firstGID
+		ext.image = resolvePath(path.Dir(source), ext.image)
+		m.tilesets[i] = ext
+	}
+
+	return m, nil
+}
+
+func isXML(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("<"))
+}
+
+func resolvePath(dir, name string) string {
+	if name == "" || path.IsAbs(name) {
+		return name
+	}
+	return path.Join(dir, name)
+}
+
+// Decodes layer data given as CSV or base64 with optional compression.
+func decodeTileData(data, encoding, compression string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, field := range strings.Split(data, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("layer data: %v", err)
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+		if err != nil {
+			return nil, fmt.Errorf("layer data: %v", err)
+		}
+
+		var r io.Reader
+		switch compression {
+		case "":
+			r = bytes.NewReader(raw)
+		case "zlib":
+			if r, err = zlib.NewReader(bytes.NewReader(raw)); err != nil {
+				return nil, fmt.Errorf("layer data: %v", err)
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(bytes.NewReader(raw)); err != nil {
+				return nil, fmt.Errorf("layer data: %v", err)
+			}
+		default:
+			return nil, fmt.Errorf("layer data: unsupported compression %q", compression)
+		}
+
+		if raw, err = ioutil.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("layer data: %v", err)
+		}
+		if len(raw)%4 != 0 {
+			return nil, fmt.Errorf("layer data: %d bytes are not a multiple of 4", len(raw))
+		}
+
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	}
+
+	return nil, fmt.Errorf("layer data: unsupported encoding %q", encoding)
+}
+
+// Parses "x,y x,y ..." point lists of TMX polygons.
+func parsePoints(s string) ([]Point, error) {
+	var points []Point
+	for _, pair := range strings.Fields(s) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("invalid point %q", pair)
+		}
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, Point{x, y})
+	}
+	return points, nil
+}
+
+// TMX, the XML format.
+
+type tmxMap struct {
+	Orientation string       `xml:"orientation,attr"`
+	Width       int          `xml:"width,attr"`
+	Height      int          `xml:"height,attr"`
+	TileWidth   int          `xml:"tilewidth,attr"`
+	TileHeight  int          `xml:"tileheight,attr"`
+	Tilesets    []tmxTileset `xml:"tileset"`
+
+	// Layers, object groups and groups in the document order.
+	Items []tmxItem `xml:",any"`
+}
+
+type tmxItem struct {
+	XMLName xml.Name
+	Name    string      `xml:"name,attr"`
+	Visible *int        `xml:"visible,attr"`
+	Opacity *float64    `xml:"opacity,attr"`
+	OffsetX float64     `xml:"offsetx,attr"`
+	OffsetY float64     `xml:"offsety,attr"`
+	Width   int         `xml:"width,attr"`
+	Height  int         `xml:"height,attr"`
+	Data    *tmxData    `xml:"data"`
+	Objects []tmxObject `xml:"object"`
+	Items   []tmxItem   `xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string     `xml:"encoding,attr"`
+	Compression string     `xml:"compression,attr"`
+	Content     string     `xml:",chardata"`
+	Tiles       []tmxTile  `xml:"tile"`
+	Chunks      []tmxChunk `xml:"chunk"`
+}
+
+type tmxChunk struct {
+	X       int       `xml:"x,attr"`
+	Y       int       `xml:"y,attr"`
+	Width   int       `xml:"width,attr"`
+	Height  int       `xml:"height,attr"`
+	Content string    `xml:",chardata"`
+	Tiles   []tmxTile `xml:"tile"`
+}
+
+type tmxTile struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32 `xml:"firstgid,attr"`
+	Source     string `xml:"source,attr"`
+	Name       string `xml:"name,attr"`
+	TileWidth  int    `xml:"tilewidth,attr"`
+	TileHeight int    `xml:"tileheight,attr"`
+	Spacing    int    `xml:"spacing,attr"`
+	Margin     int    `xml:"margin,attr"`
+	TileCount  int    `xml:"tilecount,attr"`
+	Columns    int    `xml:"columns,attr"`
+	Image      struct {
+		Source string `xml:"source,attr"`
+	} `xml:"image"`
+	Tiles []struct {
+		ID        int `xml:"id,attr"`
+		Animation []struct {
+			TileID   int `xml:"tileid,attr"`
+			Duration int `xml:"duration,attr"`
+		} `xml:"animation>frame"`
+	} `xml:"tile"`
+}
+
+type tmxObject struct {
+	ID       int       `xml:"id,attr"`
+	Name     string    `xml:"name,attr"`
+	Type     string    `xml:"type,attr"`
+	Class    string    `xml:"class,attr"`
+	X        float64   `xml:"x,attr"`
+	Y        float64   `xml:"y,attr"`
+	Width    float64   `xml:"width,attr"`
+	Height   float64   `xml:"height,attr"`
+	Rotation float64   `xml:"rotation,attr"`
+	GID      uint32    `xml:"gid,attr"`
+	Visible  *int      `xml:"visible,attr"`
+	Ellipse  *struct{} `xml:"ellipse"`
+	Point    *struct{} `xml:"point"`
+	Polygon  *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polygon"`
+	Polyline *struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polyline"`
+	Properties []struct {
+		Name    string `xml:"name,attr"`
+		Value   string `xml:"value,attr"`
+		Content string `xml:",chardata"`
+	} `xml:"properties>property"`
+}
+
+func parseTMX(data []byte) (*tiledMap, error) {
+	var tm tmxMap
+	if err := xml.Unmarshal(data, &tm); err != nil {
+		return nil, err
+	}
+
+	m := &tiledMap{
+		orientation: tm.Orientation,
+		width:       tm.Width,
+		height:      tm.Height,
+		tileWidth:   tm.TileWidth,
+		tileHeight:  tm.TileHeight,
+	}
+	for _, ts := range tm.Tilesets {
+		t := ts.tileset()
+		t.firstGID, t.source = ts.FirstGID, ts.Source
+		m.tilesets = append(m.tilesets, t)
+	}
+
+	root := tiledLayer{opacity: 1}
+	if err := m.addTMXItems(tm.Items, root); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (t tmxTileset) tileset() tiledTileset {
+	ts := tiledTileset{
+		name:       t.Name,
+		tileWidth:  t.TileWidth,
+		tileHeight: t.TileHeight,
+		spacing:    t.Spacing,
+		margin:     t.Margin,
+		columns:    t.Columns,
+		tileCount:  t.TileCount,
+		image:      t.Image.Source,
+		animations: make(map[int][]TileFrame),
+	}
+	for _, tile := range t.Tiles {
+		for _, f := range tile.Animation {
+			ts.animations[tile.ID] = append(ts.animations[tile.ID],
+				TileFrame{f.TileID, time.Duration(f.Duration) * time.Millisecond})
+		}
+	}
+	return ts
+}
+
+// Adds layers of the group, inheriting from parent.
+func (m *tiledMap) addTMXItems(items []tmxItem, parent tiledLayer) error {
+	for _, item := range items {
+		l := tiledLayer{
+			name:    item.Name,
+			hidden:  parent.hidden || (item.Visible != nil && *item.Visible == 0),
+			opacity: parent.opacity,
+			offset:  Point{parent.offset.X + item.OffsetX, parent.offset.Y + item.OffsetY},
+		}
+		if item.Opacity != nil {
+			l.opacity *= *item.Opacity
+		}
+
+		switch item.XMLName.Local {
+		case "layer":
+			if item.Data == nil {
+				continue
+			}
+			if len(item.Data.Chunks) == 0 {
+				gids, err := tmxGIDs(item.Data.Content, item.Data.Tiles, item.Data)
+				if err != nil {
+					return fmt.Errorf("layer %q: %v", item.Name, err)
+				}
+				l.chunks = []tiledChunk{{0, 0, item.Width, item.Height, gids}}
+			}
+			for _, c := range item.Data.Chunks {
+				gids, err := tmxGIDs(c.Content, c.Tiles, item.Data)
+				if err != nil {
+					return fmt.Errorf("layer %q: %v", item.Name, err)
+				}
+				l.chunks = append(l.chunks, tiledChunk{c.X, c.Y, c.Width, c.Height, gids})
+			}
+			m.layers = append(m.layers, l)
+
+		case "objectgroup":
+			l.objects = true
+			for _, o := range item.Objects {
+				obj, err := o.object()
+				if err != nil {
+					return fmt.Errorf("layer %q: object %d: %v", item.Name, o.ID, err)
+				}
+				l.objs = append(l.objs, obj)
+			}
+			m.layers = append(m.layers, l)
+
+		case "group":
+			if err := m.addTMXItems(item.Items, l); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func tmxGIDs(content string, tiles []tmxTile, data *tmxData) ([]uint32, error) {
+	if data.Encoding == "" {
+		gids := make([]uint32, len(tiles))
+		for i, t := range tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+	}
+
+	return decodeTileData(content, data.Encoding, data.Compression)
+}
+
+func (o tmxObject) object() (tiledObject, error) {
+	obj := tiledObject{
+		id: o.ID, name: o.Name, typ: o.Type,
+		x: o.X, y: o.Y, width: o.Width, height: o.Height,
+		rotation: o.Rotation,
+		gid:      o.GID,
+		ellipse:  o.Ellipse != nil,
+		point:    o.Point != nil,
+		hidden:   o.Visible != nil && *o.Visible == 0,
+	}
+	if obj.typ == "" {
+		obj.typ = o.Class
+	}
+
+	var err error
+	if o.Polygon != nil {
+		if obj.polygon, err = parsePoints(o.Polygon.Points); err != nil {
+			return obj, err
+		}
+	}
+	if o.Polyline != nil {
+		if obj.polyline, err = parsePoints(o.Polyline.Points); err != nil {
+			return obj, err
+		}
+	}
+
+	if len(o.Properties) > 0 {
+		obj.properties = make(map[string]string)
+		for _, p := range o.Properties {
+			if p.Value == "" {
+				p.Value = p.Content
+			}
+			obj.properties[p.Name] = p.Value
+		}
+	}
+
+	return obj, nil
+}
+
+// TMJ, the JSON format.
+
+type tmjMap struct {
+	Orientation string       `json:"orientation"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	TileWidth   int          `json:"tilewidth"`
+	TileHeight  int          `json:"tileheight"`
+	Tilesets    []tmjTileset `json:"tilesets"`
+	Layers      []tmjLayer   `json:"layers"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      []struct {
+		X      int             `json:"x"`
+		Y      int             `json:"y"`
+		Width  int             `json:"width"`
+		Height int             `json:"height"`
+		Data   json.RawMessage `json:"data"`
+	} `json:"chunks"`
+	Objects []tmjObject `json:"objects"`
+	Layers  []tmjLayer  `json:"layers"`
+}
+
+type tmjTileset struct {
+	FirstGID   uint32 `json:"firstgid"`
+	Source     string `json:"source"`
+	Name       string `json:"name"`
+	TileWidth  int    `json:"tilewidth"`
+	TileHeight int    `json:"tileheight"`
+	Spacing    int    `json:"spacing"`
+	Margin     int    `json:"margin"`
+	TileCount  int    `json:"tilecount"`
+	Columns    int    `json:"columns"`
+	Image      string `json:"image"`
+	Tiles      []struct {
+		ID        int `json:"id"`
+		Animation []struct {
+			TileID   int `json:"tileid"`
+			Duration int `json:"duration"`
+		} `json:"animation"`
+	} `json:"tiles"`
+}
+
+type tmjObject struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Class      string     `json:"class"`
+	X          float64    `json:"x"`
+	Y          float64    `json:"y"`
+	Width      float64    `json:"width"`
+	Height     float64    `json:"height"`
+	Rotation   float64    `json:"rotation"`
+	GID        uint32     `json:"gid"`
+	Visible    *bool      `json:"visible"`
+	Ellipse    bool       `json:"ellipse"`
+	Point      bool       `json:"point"`
+	Polygon    []tmjPoint `json:"polygon"`
+	Polyline   []tmjPoint `json:"polyline"`
+	Properties []struct {
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+	} `json:"properties"`
+}
+
+type tmjPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func parseTMJ(data []byte) (*tiledMap, error) {
+	var tm tmjMap
+	if err := json.Unmarshal(data, &tm); err != nil {
+		return nil, err
+	}
+
+	m := &tiledMap{
+		orientation: tm.Orientation,
+		width:       tm.Width,
+		height:      tm.Height,
+		tileWidth:   tm.TileWidth,
+		tileHeight:  tm.TileHeight,
+	}
+	for _, ts := range tm.Tilesets {
+		t := ts.tileset()
+		t.firstGID, t.source = ts.FirstGID, ts.Source
+		m.tilesets = append(m.tilesets, t)
+	}
+
+	root := tiledLayer{opacity: 1}
+	if err := m.addTMJLayers(tm.Layers, root); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (t tmjTileset) tileset() tiledTileset {
+	ts := tiledTileset{
+		name:       t.Name,
+		tileWidth:  t.TileWidth,
+		tileHeight: t.TileHeight,
+		spacing:    t.Spacing,
+		margin:     t.Margin,
+		columns:    t.Columns,
+		tileCount:  t.TileCount,
+		image:      t.Image,
+		animations: make(map[int][]TileFrame),
+	}
+	for _, tile := range t.Tiles {
+		for _, f := range tile.Animation {
+			ts.animations[tile.ID] = append(ts.animations[tile.ID],
+				TileFrame{f.TileID, time.Duration(f.Duration) * time.Millisecond})
+		}
+	}
+	return ts
+}
+
+func (m *tiledMap) addTMJLayers(layers []tmjLayer, parent tiledLayer) error {
+	for _, tl := range layers {
+		l := tiledLayer{
+			name:    tl.Name,
+			hidden:  parent.hidden || (tl.Visible != nil && !*tl.Visible),
+			opacity: parent.opacity,
+			offset:  Point{parent.offset.X + tl.OffsetX, parent.offset.Y + tl.OffsetY},
+		}
+		if tl.Opacity != nil {
+			l.opacity *= *tl.Opacity
+		}
+
+		switch tl.Type {
+		case "tilelayer":
+			if len(tl.Chunks) == 0 {
+				gids, err := tmjGIDs(tl.Data, tl.Encoding, tl.Compression)
+				if err != nil {
+					return fmt.Errorf("layer %q: %v", tl.Name, err)
+				}
+				l.chunks = []tiledChunk{{0, 0, tl.Width, tl.Height, gids}}
+			}
+			for _, c := range tl.Chunks {
+				gids, err := tmjGIDs(c.Data, tl.Encoding, tl.Compression)
+				if err != nil {
+					return fmt.Errorf("layer %q: %v", tl.Name, err)
+				}
+				l.chunks = append(l.chunks, tiledChunk{c.X, c.Y, c.Width, c.Height, gids})
+			}
+			m.layers = append(m.layers, l)
+
+		case "objectgroup":
+			l.objects = true
+			for _, o := range tl.Objects {
+				l.objs = append(l.objs, o.object())
+			}
+			m.layers = append(m.layers, l)
+
+		case "group":
+			if err := m.addTMJLayers(tl.Layers, l); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Decodes data given as an array of GIDs or base64 string.
+func tmjGIDs(data json.RawMessage, encoding, compression string) ([]uint32, error) {
+	if encoding != "base64" {
+		var gids []uint32
+		if err := json.Unmarshal(data, &gids); err != nil {
+			return nil, fmt.Errorf("layer data: %v", err)
+		}
+		return gids, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("layer data: %v", err)
+	}
+	return decodeTileData(s, encoding, compression)
+}
+
+func (o tmjObject) object() tiledObject {
+	obj := tiledObject{
+		id: o.ID, name: o.Name, typ: o.Type,
+		x: o.X, y: o.Y, width: o.Width, height: o.Height,
+		rotation: o.Rotation,
+		gid:      o.GID,
+		ellipse:  o.Ellipse,
+		point:    o.Point,
+		hidden:   o.Visible != nil && !*o.Visible,
+	}
+	if obj.typ == "" {
+		obj.typ = o.Class
+	}
+
+	for _, p := range o.Polygon {
+		obj.polygon = append(obj.polygon, Point{p.X, p.Y})
+	}
+	for _, p := range o.Polyline {
+		obj.polyline = append(obj.polyline, Point{p.X, p.Y})
+	}
+
+	if len(o.Properties) > 0 {
+		obj.properties = make(map[string]string)
+		for _, p := range o.Properties {
+			obj.properties[p.Name] = fmt.Sprint(p.Value)
+		}
+	}
+
+	return obj
+}
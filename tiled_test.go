@@ -0,0 +1,354 @@
+package layergl
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// The same 2x2 map of 8x8 tiles in both formats. The first layer is given
+// in every encoding, the group offsets the last of them and the objects.
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="2" height="2" tilewidth="8" tileheight="8">
+ <tileset firstgid="1" name="tiles" tilewidth="8" tileheight="8" tilecount="8" columns="4">
+  <image source="../images/tiles.png" width="32" height="16"/>
+  <tile id="3">
+   <animation>
+    <frame tileid="3" duration="100"/>
+    <frame tileid="4" duration="200"/>
+   </animation>
+  </tile>
+ </tileset>
+ <tileset firstgid="9" source="../tilesets/ext.tsx"/>
+ <layer name="csv" width="2" height="2">
+  <data encoding="csv">
+1,2147483650,
+0,536870917
+</data>
+ </layer>
+ <layer name="xml" width="2" height="2">
+  <data>
+   <tile gid="1"/>
+   <tile gid="2147483650"/>
+   <tile/>
+   <tile gid="536870917"/>
+  </data>
+ </layer>
+ <layer name="base64" width="2" height="2">
+  <data encoding="base64">
+   AQAAAAIAAIAAAAAABQAAIA==
+  </data>
+ </layer>
+ <layer name="zlib" width="2" height="2">
+  <data encoding="base64" compression="zlib">eJxjZGBgYGJgaABSDKwMDAoABOwAqQ==</data>
+ </layer>
+ <group name="group" offsetx="1" offsety="1" opacity="0.5" visible="0">
+  <layer name="gzip" width="2" height="2" opacity="0.5">
+   <data encoding="base64" compression="gzip">H4sIAAAAAAACA2NkYGBgYmBoAFIMrAwMCgDulA3EEAAAAA==</data>
+  </layer>
+  <objectgroup name="objects" offsetx="4" offsety="2">
+   <object id="1" name="box" type="wall" x="2" y="4" width="6" height="2"/>
+   <object id="2" x="0" y="0" width="4" height="2" rotation="90"/>
+   <object id="3" x="8" y="8">
+    <point/>
+   </object>
+   <object id="4" gid="9" x="0" y="16" width="8" height="8"/>
+   <object id="5" x="0" y="0">
+    <polygon points="0,0 4,0 0,4"/>
+   </object>
+   <object id="6" x="1" y="1">
+    <properties>
+     <property name="speed" type="int" value="3"/>
+    </properties>
+    <polyline points="0,0 2,2"/>
+   </object>
+  </objectgroup>
+ </group>
+</map>
+`
+
+const testTSX = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="ext" tilewidth="16" tileheight="16" tilecount="2" columns="2">
+ <image source="ext.png" width="32" height="16"/>
+</tileset>
+`
+
+const testTMJ = `{
+ "orientation": "orthogonal",
+ "width": 2, "height": 2,
+ "tilewidth": 8, "tileheight": 8,
+ "tilesets": [{
+  "firstgid": 1, "name": "tiles",
+  "tilewidth": 8, "tileheight": 8, "tilecount": 8, "columns": 4,
+  "image": "../images/tiles.png",
+  "tiles": [{"id": 3, "animation": [{"tileid": 3, "duration": 100}, {"tileid": 4, "duration": 200}]}]
+ }, {
+  "firstgid": 9, "source": "../tilesets/ext.tsj"
+ }],
+ "layers": [{
+  "type": "tilelayer", "name": "csv", "width": 2, "height": 2,
+  "data": [1, 2147483650, 0, 536870917]
+ }, {
+  "type": "tilelayer", "name": "chunks", "encoding": "base64",
+  "chunks": [
+   {"x": -2, "y": 0, "width": 2, "height": 2, "data": "AQAAAAIAAIAAAAAABQAAIA=="},
+   {"x": 0, "y": 1, "width": 1, "height": 1, "data": "AwAAAA=="}
+  ]
+ }, {
+  "type": "tilelayer", "name": "base64", "width": 2, "height": 2, "encoding": "base64",
+  "data": "AQAAAAIAAIAAAAAABQAAIA=="
+ }, {
+  "type": "tilelayer", "name": "zlib", "width": 2, "height": 2, "encoding": "base64", "compression": "zlib",
+  "data": "eJxjZGBgYGJgaABSDKwMDAoABOwAqQ=="
+ }, {
+  "type": "group", "name": "group", "offsetx": 1, "offsety": 1, "opacity": 0.5, "visible": false,
+  "layers": [{
+   "type": "tilelayer", "name": "gzip", "width": 2, "height": 2, "opacity": 0.5,
+   "encoding": "base64", "compression": "gzip",
+   "data": "H4sIAAAAAAACA2NkYGBgYmBoAFIMrAwMCgDulA3EEAAAAA=="
+  }, {
+   "type": "objectgroup", "name": "objects", "offsetx": 4, "offsety": 2,
+   "objects": [
+    {"id": 1, "name": "box", "type": "wall", "x": 2, "y": 4, "width": 6, "height": 2},
+    {"id": 2, "x": 0, "y": 0, "width": 4, "height": 2, "rotation": 90},
+    {"id": 3, "x": 8, "y": 8, "point": true},
+    {"id": 4, "gid": 9, "x": 0, "y": 16, "width": 8, "height": 8},
+    {"id": 5, "x": 0, "y": 0, "polygon": [{"x": 0, "y": 0}, {"x": 4, "y": 0}, {"x": 0, "y": 4}]},
+    {"id": 6, "x": 1, "y": 1, "polyline": [{"x": 0, "y": 0}, {"x": 2, "y": 2}],
+     "properties": [{"name": "speed", "type": "int", "value": 3}]}
+   ]
+  }]
+ }]
+}
+`
+
+const testTSJ = `{
+ "name": "ext", "tilewidth": 16, "tileheight": 16, "tilecount": 2, "columns": 2,
+ "image": "ext.png"
+}
+`
+
+var testTiledFS = fstest.MapFS{
+	"maps/level.tmx":   {Data: []byte(testTMX)},
+	"maps/level.tmj":   {Data: []byte(testTMJ)},
+	"tilesets/ext.tsx": {Data: []byte(testTSX)},
+	"tilesets/ext.tsj": {Data: []byte(testTSJ)},
+}
+
+// GIDs of the first layer: a tile, a horizontally flipped tile, no tile and
+// a diagonally flipped tile.
+var testGIDs = []uint32{1, 2 | TileFlipX, 0, 5 | TileFlipDiagonal}
+
+func TestLoadTileMap(t *testing.T) {
+	type layer struct {
+		x, y, width, height int
+		tiles               []uint32
+		offset              Point
+		opacity             float64
+		hidden              bool
+	}
+	plain := layer{0, 0, 2, 2, testGIDs, Point{}, 1, false}
+
+	tests := []struct {
+		name   string
+		layers map[string]layer
+	}{
+		{"maps/level.tmx", map[string]layer{
+			"csv":    plain,
+			"xml":    plain,
+			"base64": plain,
+			"zlib":   plain,
+			"gzip":   {0, 0, 2, 2, testGIDs, Point{1, 1}, 0.25, true},
+		}},
+		{"maps/level.tmj", map[string]layer{
+			"csv":    plain,
+			"chunks": {-2, 0, 3, 2, []uint32{1, 2 | TileFlipX, 0, 0, 5 | TileFlipDiagonal, 3}, Point{}, 1, false},
+			"base64": plain,
+			"zlib":   plain,
+			"gzip":   {0, 0, 2, 2, testGIDs, Point{1, 1}, 0.25, true},
+		}},
+	}
+
+	for _, test := range tests {
+		m, err := LoadTileMap(testTiledFS, test.name, fakeLoader{})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if m.Orientation != Orthogonal || m.Width != 2 || m.Height != 2 || m.TileWidth != 8 || m.TileHeight != 8 {
+			t.Errorf("%s: map %v %dx%d of %dx%d tiles", test.name, m.Orientation, m.Width, m.Height, m.TileWidth, m.TileHeight)
+		}
+
+		if len(m.Tilesets) != 2 {
+			t.Fatalf("%s: %d tilesets, want 2", test.name, len(m.Tilesets))
+		}
+		tiles, ext := m.Tilesets[0], m.Tilesets[1]
+		if tiles.FirstGID != 1 || tiles.Image != "images/tiles.png" || tiles.Columns != 4 || tiles.Texture == nil {
+			t.Errorf("%s: tileset %+v", test.name, *tiles)
+		}
+		frames := []TileFrame{{3, 100 * time.Millisecond}, {4, 200 * time.Millisecond}}
+		if !reflect.DeepEqual(tiles.Animations[3], frames) {
+			t.Errorf("%s: animation %v, want %v", test.name, tiles.Animations[3], frames)
+		}
+		if ext.FirstGID != 9 || ext.Name != "ext" || ext.TileWidth != 16 || ext.Image != "tilesets/ext.png" {
+			t.Errorf("%s: external tileset %+v", test.name, *ext)
+		}
+		if ts, id := m.tileset(5 | TileFlipDiagonal); ts != tiles || id != 4 {
+			t.Errorf("%s: tile 5 in tileset %q with ID %d", test.name, ts.Name, id)
+		}
+		if ts, id := m.tileset(10 | TileFlipX | TileFlipY); ts != ext || id != 1 {
+			t.Errorf("%s: tile 10 in tileset %q with ID %d", test.name, ts.Name, id)
+		}
+
+		if len(m.Layers) != len(test.layers) {
+			t.Errorf("%s: %d layers, want %d", test.name, len(m.Layers), len(test.layers))
+		}
+		for name, want := range test.layers {
+			l := m.Layer(name)
+			if l == nil {
+				t.Errorf("%s: layer %q not found", test.name, name)
+				continue
+			}
+			got := layer{l.X, l.Y, l.Width, l.Height, l.Tiles, l.Offset, l.Opacity, l.Hidden}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: layer %q is %+v, want %+v", test.name, name, got, want)
+			}
+		}
+
+		ol := m.ObjectLayer("objects")
+		if ol == nil || !ol.Hidden {
+			t.Fatalf("%s: object layer %+v, want hidden", test.name, ol)
+		}
+		checkTestObjects(t, test.name, ol.Objects)
+	}
+}
+
+// Checks the objects of the test maps, offset by 5, 3 in a map 16 pixels
+// high.
+func checkTestObjects(t *testing.T, name string, objects []MapObject) {
+	t.Helper()
+
+	tests := []struct {
+		kind     MapObjectKind
+		position Point
+		vertices []Point
+	}{
+		{ObjectRectangle, Point{7, 9}, []Point{{7, 9}, {13, 9}, {13, 7}, {7, 7}}},
+		{ObjectRectangle, Point{5, 13}, []Point{{5, 13}, {5, 9}, {3, 9}, {3, 13}}},
+		{ObjectPoint, Point{13, 5}, []Point{{13, 5}}},
+		{ObjectTile, Point{5, -3}, []Point{{5, 5}, {13, 5}, {13, -3}, {5, -3}}},
+		{ObjectPolygon, Point{5, 13}, nil},
+		{ObjectPolyline, Point{6, 12}, []Point{{6, 12}, {8, 10}}},
+	}
+
+	if len(objects) != len(tests) {
+		t.Fatalf("%s: %d objects, want %d", name, len(objects), len(tests))
+	}
+	for i, want := range tests {
+		o := objects[i]
+		if o.ID != i+1 || o.Kind != want.kind || !nearPoint(o.Position, want.position) {
+			t.Errorf("%s: object %d is %v %v at %v, want %v at %v", name, i+1, o.ID, o.Kind, o.Position, want.kind, want.position)
+		}
+		if want.vertices == nil {
+			continue
+		}
+		if len(o.Shape.Vertices) != len(want.vertices) {
+			t.Errorf("%s: object %d has vertices %v, want %v", name, o.ID, o.Shape.Vertices, want.vertices)
+			continue
+		}
+		for j, v := range want.vertices {
+			if !nearPoint(o.Shape.Vertices[j], v) {
+				t.Errorf("%s: object %d has vertices %v, want %v", name, o.ID, o.Shape.Vertices, want.vertices)
+				break
+			}
+		}
+	}
+
+	if o := objects[0]; o.Name != "box" || o.Type != "wall" {
+		t.Errorf("%s: object 1 is %q of type %q", name, o.Name, o.Type)
+	}
+	if o := objects[3]; o.GID != 9 {
+		t.Errorf("%s: tile object GID %d, want 9", name, o.GID)
+	}
+	if o := objects[4]; len(o.Shape.Indices) != 3 {
+		t.Errorf("%s: polygon indices %v", name, o.Shape.Indices)
+	}
+	if o := objects[5]; !reflect.DeepEqual(o.Properties, map[string]string{"speed": "3"}) {
+		t.Errorf("%s: properties %v", name, o.Properties)
+	}
+}
+
+func nearPoint(a, b Point) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestIsometricObjectPosition(t *testing.T) {
+	m := &TileMap{Orientation: Isometric, Width: 2, Height: 2, TileWidth: 16, TileHeight: 8}
+
+	tests := []struct {
+		x, y float64
+		want Point
+	}{
+		// The top corner of the map.
+		{0, 0, Point{16, 16}},
+		// Along the X and Y tile axes.
+		{8, 0, Point{24, 12}},
+		{0, 8, Point{8, 12}},
+		// The bottom corner.
+		{16, 16, Point{16, 0}},
+	}
+	for _, test := range tests {
+		o := m.object(tiledObject{x: test.x, y: test.y, point: true}, Point{})
+		if !nearPoint(o.Position, test.want) {
+			t.Errorf("object at %v, %v: position %v, want %v", test.x, test.y, o.Position, test.want)
+		}
+	}
+}
+
+func TestDecodeTileDataErrors(t *testing.T) {
+	tests := []struct {
+		data, encoding, compression string
+	}{
+		{"1,x", "csv", ""},
+		{"!!!", "base64", ""},
+		{"AQAA", "base64", ""},
+		{"AQAAAA==", "base64", "zstd"},
+		{"AQAAAA==", "base64", "gzip"},
+		{"1", "xml", ""},
+	}
+	for _, test := range tests {
+		if gids, err := decodeTileData(test.data, test.encoding, test.compression); err == nil {
+			t.Errorf("%q as %s/%s decoded to %v, want error", test.data, test.encoding, test.compression, gids)
+		}
+	}
+}
+
+func TestTileUVsFlip(t *testing.T) {
+	ts := &Tileset{TileWidth: 8, TileHeight: 8, Columns: 4, Texture: &Texture{size: image.Point{32, 16}}}
+
+	// Corners of tile 1 in the texture, in the order of tileQuad.
+	bl, tl, br, tr := Point{0.25, 0.5}, Point{0.25, 1}, Point{0.5, 0.5}, Point{0.5, 1}
+
+	tests := []struct {
+		name  string
+		flags uint32
+		want  [4]Point
+	}{
+		{"none", 0, [4]Point{bl, tl, br, tr}},
+		{"horizontal", TileFlipX, [4]Point{br, tr, bl, tl}},
+		{"vertical", TileFlipY, [4]Point{tl, bl, tr, br}},
+		{"both", TileFlipX | TileFlipY, [4]Point{tr, br, tl, bl}},
+		{"diagonal", TileFlipDiagonal, [4]Point{tr, tl, br, bl}},
+		// Tiled rotates tiles by 90° clockwise this way.
+		{"rotated", TileFlipDiagonal | TileFlipX, [4]Point{br, bl, tr, tl}},
+		{"rotated back", TileFlipDiagonal | TileFlipY, [4]Point{tl, tr, bl, br}},
+	}
+	for _, test := range tests {
+		if got := tileUVs(ts, 1, 2|test.flags); got != test.want {
+			t.Errorf("%s: UVs %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,704 @@
+package layergl
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"time"
+)
+
+type TileOrientation int
+
+const (
+	Orthogonal TileOrientation = iota
+	Isometric
+)
+
+// Flags in the high bits of global tile IDs.
+const (
+	TileFlipX        uint32 = 0x80000000
+	TileFlipY        uint32 = 0x40000000
+	TileFlipDiagonal uint32 = 0x20000000
+
+	// Also masks the hexagonal rotation flag, which is ignored.
+	tileFlags uint32 = 0xF0000000
+)
+
+// Side of the square of tiles drawn with a single mesh.
+const tileChunkSize = 16
+
+// TileMap is a map of tiles and objects loaded from Tiled. Map coordinates
+// are in pixels with the Y axis pointing up and the map occupying Bounds, so
+// the first row of tiles is at the top as in Tiled.
+type TileMap struct {
+	Orientation           TileOrientation
+	Width, Height         int
+	TileWidth, TileHeight int
+
+	Tilesets     []*Tileset
+	Layers       []*TileLayer
+	ObjectLayers []*ObjectLayer
+
+	elapsed time.Duration
+}
+
+// Tileset is an image of tiles laid out in a grid. Tilesets without image
+// are not drawn.
+type Tileset struct {
+	Name                  string
+	FirstGID              uint32
+	TileWidth, TileHeight int
+	Spacing, Margin       int
+	Columns, TileCount    int
+
+	// Asset path of the image and the texture loaded from it.
+	Image   string
+	Texture *Texture
+
+	// Animation frames by local tile ID.
+	Animations map[int][]TileFrame
+}
+
+// TileFrame is a frame of an animated tile.
+type TileFrame struct {
+	TileID   int
+	Duration time.Duration
+}
+
+// TileLayer is a grid of global tile IDs. Layers of infinite maps may start
+// at non-zero tile coordinates.
+type TileLayer struct {
+	Name          string
+	X, Y          int
+	Width, Height int
+
+	// Global tile IDs with the flip flags, row by row. Zero is no tile. Use
+	// SetTile to change tiles after the layer was drawn.
+	Tiles []uint32
+
+	// Offset in pixels, with Y pointing down as in Tiled.
+	Offset  Point
+	Opacity float64
+	Hidden  bool
+
+	chunks       []*tileChunk
+	chunkColumns int
+}
+
+type ObjectLayer struct {
+	Name    string
+	Hidden  bool
+	Objects []MapObject
+}
+
+type MapObjectKind int
+
+const (
+	ObjectRectangle MapObjectKind = iota
+	ObjectEllipse
+	ObjectPoint
+	ObjectPolygon
+	ObjectPolyline
+	ObjectTile
+)
+
+// MapObject is an object of an object layer. Its shape is given in map
+// coordinates, with the layer offset and the rotation applied, so it can be
+// used for collision directly. Polylines and points have no indices.
+type MapObject struct {
+	ID         int
+	Name, Type string
+	Kind       MapObjectKind
+	Position   Point
+	Shape      *VertexObject
+	GID        uint32
+	Hidden     bool
+	Properties map[string]string
+}
+
+// Geometry of tiles of a layer within one chunk.
+type tileChunk struct {
+	parts  []*tileChunkPart
+	bounds Rect
+	dirty  bool
+}
+
+// Tiles of a chunk using the same tileset.
+type tileChunkPart struct {
+	tileset *Tileset
+	mesh    *Mesh
+
+	animated   []animatedTile
+	animMesh   *Mesh
+	animFrames []int
+}
+
+type animatedTile struct {
+	quad [4]Point
+	id   int
+	gid  uint32
+}
+
+// Loads Tiled map in the .tmx or .tmj format from fsys, including external
+// tilesets. Tileset images are loaded with the loader, relative to fsys. A nil
+// loader loads no textures, which is enough for reading objects.
+func LoadTileMap(fsys fs.FS, name string, loader AssetLoader) (*TileMap, error) {
+	tm, err := readTiledMap(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &TileMap{
+		Width:      tm.width,
+		Height:     tm.height,
+		TileWidth:  tm.tileWidth,
+		TileHeight: tm.tileHeight,
+	}
+	switch tm.orientation {
+	case "orthogonal":
+		m.Orientation = Orthogonal
+	case "isometric":
+		m.Orientation = Isometric
+	default:
+		return nil, fmt.Errorf("%s: unsupported orientation %q", name, tm.orientation)
+	}
+
+	for _, t := range tm.tilesets {
+		ts := &Tileset{
+			Name:       t.name,
+			FirstGID:   t.firstGID,
+			TileWidth:  t.tileWidth,
+			TileHeight: t.tileHeight,
+			Spacing:    t.spacing,
+			Margin:     t.margin,
+			Columns:    t.columns,
+			TileCount:  t.tileCount,
+			Image:      t.image,
+			Animations: t.animations,
+		}
+		if loader != nil && ts.Image != "" {
+			if ts.Texture, err = loader.LoadTexture(ts.Image); err != nil {
+				return nil, fmt.Errorf("%s: tileset %q: missing image %q: %v", name, ts.Name, ts.Image, err)
+			}
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	for _, l := range tm.layers {
+		if l.objects {
+			ol := &ObjectLayer{Name: l.name, Hidden: l.hidden}
+			for _, o := range l.objs {
+				ol.Objects = append(ol.Objects, m.object(o, l.offset))
+			}
+			m.ObjectLayers = append(m.ObjectLayers, ol)
+		} else {
+			m.Layers = append(m.Layers, tileLayer(l))
+		}
+	}
+
+	return m, nil
+}
+
+// Merges chunks of the layer into a single grid.
+func tileLayer(l tiledLayer) *TileLayer {
+	tl := &TileLayer{Name: l.name, Offset: l.offset, Opacity: l.opacity, Hidden: l.hidden}
+	if len(l.chunks) == 0 {
+		return tl
+	}
+
+	x1, y1 := l.chunks[0].x, l.chunks[0].y
+	x2, y2 := x1, y1
+	for _, c := range l.chunks {
+		x1, y1 = minInt(x1, c.x), minInt(y1, c.y)
+		x2, y2 = maxInt(x2, c.x+c.width), maxInt(y2, c.y+c.height)
+	}
+
+	tl.X, tl.Y = x1, y1
+	tl.Width, tl.Height = x2-x1, y2-y1
+	tl.Tiles = make([]uint32, tl.Width*tl.Height)
+	for _, c := range l.chunks {
+		for i, gid := range c.gids {
+			if i >= c.width*c.height {
+				break
+			}
+			x, y := c.x+i%c.width-x1, c.y+i/c.width-y1
+			tl.Tiles[y*tl.Width+x] = gid
+		}
+	}
+
+	return tl
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Returns the tile layer with the name, or nil.
+func (m *TileMap) Layer(name string) *TileLayer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Returns the object layer with the name, or nil.
+func (m *TileMap) ObjectLayer(name string) *ObjectLayer {
+	for _, l := range m.ObjectLayers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Returns the rectangle covered by the map, not including layer offsets and
+// tiles larger than the map grid.
+func (m *TileMap) Bounds() Rect {
+	if m.Orientation == Isometric {
+		return Rect{0, 0, float64((m.Width + m.Height) * m.TileWidth / 2), m.pixelHeight()}
+	}
+	return Rect{0, 0, float64(m.Width * m.TileWidth), m.pixelHeight()}
+}
+
+func (m *TileMap) pixelHeight() float64 {
+	if m.Orientation == Isometric {
+		return float64((m.Width + m.Height) * m.TileHeight / 2)
+	}
+	return float64(m.Height * m.TileHeight)
+}
+
+// Converts Tiled pixel coordinates, with Y pointing down, to map coordinates.
+func (m *TileMap) toMap(p, offset Point) Point {
+	return Point{p.X + offset.X, m.pixelHeight() - p.Y - offset.Y}
+}
+
+// Converts object coordinates to Tiled pixel coordinates. Objects of
+// isometric maps are positioned along the tile axes.
+func (m *TileMap) objectPixel(p Point) Point {
+	if m.Orientation != Isometric {
+		return p
+	}
+
+	th, tw := float64(m.TileHeight), float64(m.TileWidth)
+	return Point{
+		(p.X-p.Y)/th*tw/2 + float64(m.Height)*tw/2,
+		(p.X + p.Y) / 2,
+	}
+}
+
+func (m *TileMap) object(o tiledObject, offset Point) MapObject {
+	obj := MapObject{
+		ID:         o.id,
+		Name:       o.name,
+		Type:       o.typ,
+		GID:        o.gid,
+		Hidden:     o.hidden,
+		Properties: o.properties,
+	}
+
+	sin, cos := math.Sincos(o.rotation * math.Pi / 180)
+	origin := Point{o.x, o.y}
+	project := func(points []Point) []Point {
+		projected := make([]Point, len(points))
+		for i, p := range points {
+			rotated := Point{o.x + p.X*cos - p.Y*sin, o.y + p.X*sin + p.Y*cos}
+			projected[i] = m.toMap(m.objectPixel(rotated), offset)
+		}
+		return projected
+	}
+	obj.Position = m.toMap(m.objectPixel(origin), offset)
+
+	v := new(VertexObject)
+	switch {
+	case o.gid != 0:
+		// Tile objects are aligned by the bottom left corner.
+		obj.Kind = ObjectTile
+		v.Vertices = project([]Point{{0, -o.height}, {o.width, -o.height}, {o.width, 0}, {0, 0}})
+		v.Indices = []int{0, 1, 2, 0, 2, 3}
+
+	case o.ellipse:
+		obj.Kind = ObjectEllipse
+		const segments = 16
+		points := make([]Point, segments)
+		for i := range points {
+			sin, cos := math.Sincos(2 * math.Pi * float64(i) / segments)
+			points[i] = Point{o.width / 2 * (1 + cos), o.height / 2 * (1 + sin)}
+		}
+		v.Vertices = project(points)
+		for i := 1; i+1 < segments; i++ {
+			v.Indices = append(v.Indices, 0, i, i+1)
+		}
+
+	case o.point:
+		obj.Kind = ObjectPoint
+		v.Vertices = project([]Point{{0, 0}})
+
+	case o.polygon != nil:
+		obj.Kind = ObjectPolygon
+		v.Vertices = project(o.polygon)
+		if err := v.Triangulate(); err != nil {
+			// Ear clipping depends on the winding, which the Y axis flip reverses.
+			for i, j := 0, len(v.Vertices)-1; i < j; i, j = i+1, j-1 {
+				v.Vertices[i], v.Vertices[j] = v.Vertices[j], v.Vertices[i]
+			}
+			if err := v.Triangulate(); err != nil {
+				log.Printf("TileMap: object %d: %v", o.id, err)
+			}
+		}
+
+	case o.polyline != nil:
+		obj.Kind = ObjectPolyline
+		v.Vertices = project(o.polyline)
+
+	default:
+		obj.Kind = ObjectRectangle
+		v.Vertices = project([]Point{{0, 0}, {o.width, 0}, {o.width, o.height}, {0, o.height}})
+		v.Indices = []int{0, 1, 2, 0, 2, 3}
+	}
+	obj.Shape = v
+
+	return obj
+}
+
+// Returns global tile ID with flags at the tile coordinates, or zero outside
+// of the layer.
+func (l *TileLayer) Tile(x, y int) uint32 {
+	x, y = x-l.X, y-l.Y
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.Tiles[y*l.Width+x]
+}
+
+// Sets global tile ID with flags at the tile coordinates, which must be
+// within the layer. Only the chunk containing the tile is rebuilt.
+func (l *TileLayer) SetTile(x, y int, gid uint32) {
+	x, y = x-l.X, y-l.Y
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		log.Printf("TileLayer.SetTile: %d, %d is outside of layer %q.", x+l.X, y+l.Y, l.Name)
+		return
+	}
+
+	l.Tiles[y*l.Width+x] = gid
+	if l.chunks != nil {
+		l.chunks[(y/tileChunkSize)*l.chunkColumns+x/tileChunkSize].dirty = true
+	}
+}
+
+// Returns the tileset of the global tile ID and the local ID of the tile.
+func (m *TileMap) tileset(gid uint32) (*Tileset, int) {
+	gid &^= tileFlags
+	var ts *Tileset
+	for _, t := range m.Tilesets {
+		if t.FirstGID <= gid && (ts == nil || t.FirstGID > ts.FirstGID) {
+			ts = t
+		}
+	}
+	if ts == nil {
+		return nil, 0
+	}
+	return ts, int(gid - ts.FirstGID)
+}
+
+// Advances animated tiles. All animations of the map share one clock.
+func (m *TileMap) Update(dt time.Duration) {
+	m.elapsed += dt
+}
+
+// Returns the tile shown by the animated tile at the current time.
+func (m *TileMap) animationFrame(frames []TileFrame) int {
+	var total time.Duration
+	for _, f := range frames {
+		total += f.Duration
+	}
+	if total <= 0 {
+		return frames[0].TileID
+	}
+
+	t := m.elapsed % total
+	for _, f := range frames {
+		if t < f.Duration {
+			return f.TileID
+		}
+		t -= f.Duration
+	}
+	return frames[len(frames)-1].TileID
+}
+
+// Returns corners of the tile image at the tile coordinates in the order of
+// Rectangle: bottom left, top left, bottom right, top right.
+func (m *TileMap) tileQuad(l *TileLayer, x, y int, ts *Tileset) [4]Point {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+
+	// Bottom left corner of the cell in Tiled pixels.
+	var bottomLeft Point
+	if m.Orientation == Isometric {
+		top := Point{float64(x-y)*tw/2 + float64(m.Height)*tw/2, float64(x+y) * th / 2}
+		bottomLeft = Point{top.X - tw/2, top.Y + th}
+	} else {
+		bottomLeft = Point{float64(x) * tw, float64(y+1) * th}
+	}
+
+	p := m.toMap(bottomLeft, l.Offset)
+	w, h := float64(ts.TileWidth), float64(ts.TileHeight)
+	return [4]Point{{p.X, p.Y}, {p.X, p.Y + h}, {p.X + w, p.Y}, {p.X + w, p.Y + h}}
+}
+
+// Returns texture coordinates of the tile corners, see tileQuad.
+func tileUVs(ts *Tileset, id int, gid uint32) [4]Point {
+	if ts.Texture == nil {
+		return [4]Point{}
+	}
+
+	iw, ih := ts.Texture.Size()
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = (iw - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+	if columns <= 0 {
+		columns = 1
+	}
+
+	x := float64(ts.Margin + id%columns*(ts.TileWidth+ts.Spacing))
+	y := float64(ts.Margin + id/columns*(ts.TileHeight+ts.Spacing))
+
+	// Corners in the image, Y pointing down.
+	corners := [4]Point{{0, 1}, {0, 0}, {1, 1}, {1, 0}}
+	var uvs [4]Point
+	for i, q := range corners {
+		if gid&TileFlipX != 0 {
+			q.X = 1 - q.X
+		}
+		if gid&TileFlipY != 0 {
+			q.Y = 1 - q.Y
+		}
+		if gid&TileFlipDiagonal != 0 {
+			q.X, q.Y = q.Y, q.X
+		}
+
+		uvs[i] = Point{
+			(x + q.X*float64(ts.TileWidth)) / float64(iw),
+			1 - (y+q.Y*float64(ts.TileHeight))/float64(ih),
+		}
+	}
+	return uvs
+}
+
+func appendTile(v *VertexObject, quad, uvs [4]Point) {
+	n := len(v.Vertices)
+	v.Vertices = append(v.Vertices, quad[:]...)
+	v.UVs = append(v.UVs, uvs[:]...)
+	v.Indices = append(v.Indices, n, n+1, n+2, n+1, n+2, n+3)
+}
+
+// Creates empty chunks of the layer, built when first drawn.
+func (m *TileMap) initChunks(l *TileLayer) {
+	l.chunkColumns = (l.Width + tileChunkSize - 1) / tileChunkSize
+	rows := (l.Height + tileChunkSize - 1) / tileChunkSize
+	l.chunks = make([]*tileChunk, l.chunkColumns*rows)
+	for i := range l.chunks {
+		l.chunks[i] = &tileChunk{dirty: true}
+	}
+}
+
+// Rebuilds meshes of the chunk at the index.
+func (m *TileMap) buildChunk(l *TileLayer, index int) {
+	c := l.chunks[index]
+	c.delete()
+
+	x0 := index % l.chunkColumns * tileChunkSize
+	y0 := index / l.chunkColumns * tileChunkSize
+
+	geometry := make(map[*tileChunkPart]*VertexObject)
+	parts := make(map[*Tileset]*tileChunkPart)
+	first := true
+	for y := y0; y < minInt(y0+tileChunkSize, l.Height); y++ {
+		for x := x0; x < minInt(x0+tileChunkSize, l.Width); x++ {
+			gid := l.Tiles[y*l.Width+x]
+			if gid == 0 {
+				continue
+			}
+			ts, id := m.tileset(gid)
+			if ts == nil {
+				continue
+			}
+
+			part := parts[ts]
+			if part == nil {
+				part = &tileChunkPart{tileset: ts}
+				parts[ts] = part
+				geometry[part] = new(VertexObject)
+				c.parts = append(c.parts, part)
+			}
+
+			quad := m.tileQuad(l, x+l.X, y+l.Y, ts)
+			if _, ok := ts.Animations[id]; ok {
+				part.animated = append(part.animated, animatedTile{quad, id, gid})
+			} else {
+				appendTile(geometry[part], quad, tileUVs(ts, id, gid))
+			}
+
+			bounds := Rect{quad[0].X, quad[0].Y, quad[3].X, quad[3].Y}
+			if first {
+				c.bounds, first = bounds, false
+			} else {
+				c.bounds = unionRect(c.bounds, bounds)
+			}
+		}
+	}
+
+	for _, part := range c.parts {
+		if v := geometry[part]; len(v.Vertices) > 0 {
+			part.mesh = NewMesh(v)
+		}
+	}
+	c.dirty = false
+}
+
+// Returns area covered by any tiles the chunk at the index may contain,
+// before its meshes are built.
+func (m *TileMap) chunkArea(l *TileLayer, index int) Rect {
+	largest := &Tileset{TileWidth: m.TileWidth, TileHeight: m.TileHeight}
+	for _, ts := range m.Tilesets {
+		largest.TileWidth = maxInt(largest.TileWidth, ts.TileWidth)
+		largest.TileHeight = maxInt(largest.TileHeight, ts.TileHeight)
+	}
+
+	x0 := index % l.chunkColumns * tileChunkSize
+	y0 := index / l.chunkColumns * tileChunkSize
+	x1 := minInt(x0+tileChunkSize, l.Width) - 1
+	y1 := minInt(y0+tileChunkSize, l.Height) - 1
+
+	// Tiles are placed linearly in the cell coordinates, so the corner cells
+	// cover the extremes.
+	var area Rect
+	for i, cell := range [][2]int{{x0, y0}, {x1, y0}, {x0, y1}, {x1, y1}} {
+		quad := m.tileQuad(l, cell[0]+l.X, cell[1]+l.Y, largest)
+		bounds := Rect{quad[0].X, quad[0].Y, quad[3].X, quad[3].Y}
+		if i == 0 {
+			area = bounds
+		} else {
+			area = unionRect(area, bounds)
+		}
+	}
+
+	return area
+}
+
+func unionRect(a, b Rect) Rect {
+	return Rect{
+		math.Min(a.X1, b.X1), math.Min(a.Y1, b.Y1),
+		math.Max(a.X2, b.X2), math.Max(a.Y2, b.Y2),
+	}
+}
+
+func overlaps(a, b Rect) bool {
+	return a.X1 <= b.X2 && b.X1 <= a.X2 && a.Y1 <= b.Y2 && b.Y1 <= a.Y2
+}
+
+// Rebuilds the mesh of animated tiles if any of them changed the frame.
+func (m *TileMap) updateAnimated(part *tileChunkPart) {
+	changed := part.animMesh == nil
+	if len(part.animFrames) != len(part.animated) {
+		part.animFrames = make([]int, len(part.animated))
+		changed = true
+	}
+	for i, t := range part.animated {
+		frame := m.animationFrame(part.tileset.Animations[t.id])
+		if frame != part.animFrames[i] {
+			part.animFrames[i] = frame
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	v := new(VertexObject)
+	for i, t := range part.animated {
+		appendTile(v, t.quad, tileUVs(part.tileset, part.animFrames[i], t.gid))
+	}
+	if part.animMesh == nil {
+		part.animMesh = NewMesh(v)
+	} else {
+		part.animMesh.Update(v)
+	}
+}
+
+func (c *tileChunk) delete() {
+	for _, part := range c.parts {
+		if part.mesh != nil {
+			part.mesh.Delete()
+		}
+		if part.animMesh != nil {
+			part.animMesh.Delete()
+		}
+	}
+	c.parts = nil
+}
+
+// Deletes meshes of the map. Textures belong to the loader. The map may be
+// drawn again.
+func (m *TileMap) Delete() {
+	for _, l := range m.Layers {
+		for _, c := range l.chunks {
+			c.delete()
+		}
+		l.chunks = nil
+	}
+}
+
+// Draws visible tile layers of the map. Only chunks intersecting the view,
+// given in map coordinates, are drawn; pass Bounds to draw the whole map.
+// Chunk meshes are built when the chunk is first visible and after SetTile.
+func DrawTileMap(m *TileMap, view Rect) {
+	for _, l := range m.Layers {
+		if l.Hidden || l.Opacity <= 0 {
+			continue
+		}
+		if l.chunks == nil {
+			m.initChunks(l)
+		}
+
+		textureShader.SetFloat("transparency", float32(1-l.Opacity))
+		for i, c := range l.chunks {
+			if c.dirty {
+				// Chunks outside of the view stay dirty until they are seen.
+				if !overlaps(m.chunkArea(l, i), view) {
+					continue
+				}
+				m.buildChunk(l, i)
+			}
+			if len(c.parts) == 0 || !overlaps(c.bounds, view) {
+				continue
+			}
+
+			for _, part := range c.parts {
+				if part.tileset.Texture == nil {
+					continue
+				}
+				if part.mesh != nil {
+					DrawMeshTexture(part.mesh, part.tileset.Texture)
+				}
+				if len(part.animated) > 0 {
+					m.updateAnimated(part)
+					DrawMeshTexture(part.animMesh, part.tileset.Texture)
+				}
+			}
+		}
+		textureShader.SetFloat("transparency", 0)
+	}
+}
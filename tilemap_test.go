@@ -0,0 +1,38 @@
+package layergl
+
+import (
+	"testing"
+)
+
+func TestChunkAreaCoversTiles(t *testing.T) {
+	for _, orientation := range []TileOrientation{Orthogonal, Isometric} {
+		m := &TileMap{
+			Orientation: orientation,
+			Width:       40, Height: 20,
+			TileWidth: 32, TileHeight: 16,
+			Tilesets: []*Tileset{
+				{TileWidth: 32, TileHeight: 16},
+				{TileWidth: 64, TileHeight: 96},
+			},
+		}
+		l := &TileLayer{X: -3, Y: 2, Width: 40, Height: 20, Offset: Point{5, -7}}
+		m.initChunks(l)
+
+		for i := range l.chunks {
+			area := m.chunkArea(l, i)
+			x0 := i % l.chunkColumns * tileChunkSize
+			y0 := i / l.chunkColumns * tileChunkSize
+
+			for y := y0; y < minInt(y0+tileChunkSize, l.Height); y++ {
+				for x := x0; x < minInt(x0+tileChunkSize, l.Width); x++ {
+					for _, ts := range m.Tilesets {
+						q := m.tileQuad(l, x+l.X, y+l.Y, ts)
+						if !overlaps(Rect{q[0].X, q[0].Y, q[0].X, q[0].Y}, area) || !overlaps(Rect{q[3].X, q[3].Y, q[3].X, q[3].Y}, area) {
+							t.Fatalf("orientation %v chunk %d: area %v does not cover tile %d, %d at %v", orientation, i, area, x, y, q)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package layergl
+
+import (
+	"log"
+	"math"
+)
+
+// Transform is a 2D affine transformation mapping point (x, y) to
+//
+//	x' = A*x + C*y + E
+//	y' = B*x + D*y + F
+type Transform struct {
+	A, B, C, D, E, F float64
+}
+
+// Returns the identity transformation.
+func Identity() Transform {
+	return Transform{A: 1, D: 1}
+}
+
+func Translation(x, y float64) Transform {
+	return Transform{A: 1, D: 1, E: x, F: y}
+}
+
+// Rotation by angle in radians around the origin.
+func Rotation(angle float64) Transform {
+	sin, cos := math.Sincos(angle)
+	return Transform{A: cos, B: sin, C: -sin, D: cos}
+}
+
+func Scaling(x, y float64) Transform {
+	return Transform{A: x, D: y}
+}
+
+// Returns transformation applying u first and then t.
+func (t Transform) Mul(u Transform) Transform {
+	return Transform{
+		A: t.A*u.A + t.C*u.B,
+		B: t.B*u.A + t.D*u.B,
+		C: t.A*u.C + t.C*u.D,
+		D: t.B*u.C + t.D*u.D,
+		E: t.A*u.E + t.C*u.F + t.E,
+		F: t.B*u.E + t.D*u.F + t.F,
+	}
+}
+
+// Returns transformed point.
+func (t Transform) Apply(p Point) Point {
+	return Point{t.A*p.X + t.C*p.Y + t.E, t.B*p.X + t.D*p.Y + t.F}
+}
+
+// Returns the inverse transformation. Singular transformations are returned
+// unchanged.
+func (t Transform) Invert() Transform {
+	det := t.A*t.D - t.B*t.C
+	if det == 0 {
+		return t
+	}
+
+	return Transform{
+		A: t.D / det,
+		B: -t.B / det,
+		C: -t.C / det,
+		D: t.A / det,
+		E: (t.C*t.F - t.D*t.E) / det,
+		F: (t.B*t.E - t.A*t.F) / det,
+	}
+}
+
+// Returns the factor by which the transformation scales lengths on average.
+func (t Transform) scaleFactor() float64 {
+	return math.Sqrt(math.Abs(t.A*t.D - t.B*t.C))
+}
+
+// Returns bounds of the rectangle after transformation.
+func (t Transform) applyRect(r Rect) Rect {
+	return VertexObject{Vertices: []Point{
+		t.Apply(Point{r.X1, r.Y1}),
+		t.Apply(Point{r.X1, r.Y2}),
+		t.Apply(Point{r.X2, r.Y1}),
+		t.Apply(Point{r.X2, r.Y2}),
+	}}.Bounds()
+}
+
+// Returns the transformation as column-major 4x4 matrix.
+func (t Transform) matrix() []float32 {
+	return []float32{
+		float32(t.A), float32(t.B), 0, 0,
+		float32(t.C), float32(t.D), 0, 0,
+		0, 0, 1, 0,
+		float32(t.E), float32(t.F), 0, 1,
+	}
+}
+
+var (
+	transform      = Identity()
+	transformStack []Transform
+)
+
+// Multiplies the current transformation by t, so that t is applied to the
+// geometry of the following draw calls before the current transformation.
+func PushTransform(t Transform) {
+	transformStack = append(transformStack, transform)
+	setTransform(transform.Mul(t))
+}
+
+// Restores the transformation active before the matching PushTransform.
+func PopTransform() {
+	if len(transformStack) == 0 {
+		log.Println("PopTransform: transform stack is empty.")
+		return
+	}
+
+	setTransform(transformStack[len(transformStack)-1])
+	transformStack = transformStack[:len(transformStack)-1]
+}
+
+// Returns the current transformation.
+func CurrentTransform() Transform {
+	return transform
+}
+
+func setTransform(t Transform) {
+	transform = t
+	projectionMatrix = multiplyMatrices(screenProjection, t.matrix())
+}
+
+// Product of two column-major 4x4 matrices.
+func multiplyMatrices(a, b []float32) []float32 {
+	m := make([]float32, 16)
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			var sum float32
+			for k := 0; k < 4; k++ {
+				sum += a[k*4+row] * b[col*4+k]
+			}
+			m[col*4+row] = sum
+		}
+	}
+
+	return m
+}
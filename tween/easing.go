@@ -0,0 +1,233 @@
+package tween
+
+import (
+	"math"
+)
+
+// Easing maps the linear progress of a tween, from 0 to 1, to the progress
+// of the animated value. It returns 0 at 0 and 1 at 1, but may overshoot in
+// between.
+type Easing func(t float64) float64
+
+func Linear(t float64) float64 {
+	return t
+}
+
+func QuadIn(t float64) float64    { return powIn(t, 2) }
+func QuadOut(t float64) float64   { return powOut(t, 2) }
+func QuadInOut(t float64) float64 { return powInOut(t, 2) }
+
+func CubicIn(t float64) float64    { return powIn(t, 3) }
+func CubicOut(t float64) float64   { return powOut(t, 3) }
+func CubicInOut(t float64) float64 { return powInOut(t, 3) }
+
+func QuartIn(t float64) float64    { return powIn(t, 4) }
+func QuartOut(t float64) float64   { return powOut(t, 4) }
+func QuartInOut(t float64) float64 { return powInOut(t, 4) }
+
+func QuintIn(t float64) float64    { return powIn(t, 5) }
+func QuintOut(t float64) float64   { return powOut(t, 5) }
+func QuintInOut(t float64) float64 { return powInOut(t, 5) }
+
+func powIn(t, n float64) float64 {
+	return math.Pow(t, n)
+}
+
+func powOut(t, n float64) float64 {
+	return 1 - math.Pow(1-t, n)
+}
+
+func powInOut(t, n float64) float64 {
+	if t < 0.5 {
+		return math.Pow(2, n-1) * math.Pow(t, n)
+	}
+	return 1 - math.Pow(2-2*t, n)/2
+}
+
+func SineIn(t float64) float64 {
+	return 1 - math.Cos(t*math.Pi/2)
+}
+
+func SineOut(t float64) float64 {
+	return math.Sin(t * math.Pi / 2)
+}
+
+func SineInOut(t float64) float64 {
+	return (1 - math.Cos(t*math.Pi)) / 2
+}
+
+func ExpoIn(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Pow(2, 10*t-10)
+}
+
+func ExpoOut(t float64) float64 {
+	if t >= 1 {
+		return 1
+	}
+	return 1 - math.Pow(2, -10*t)
+}
+
+func ExpoInOut(t float64) float64 {
+	switch {
+	case t <= 0:
+		return 0
+	case t >= 1:
+		return 1
+	case t < 0.5:
+		return math.Pow(2, 20*t-10) / 2
+	default:
+		return (2 - math.Pow(2, 10-20*t)) / 2
+	}
+}
+
+func CircIn(t float64) float64 {
+	return 1 - math.Sqrt(1-t*t)
+}
+
+func CircOut(t float64) float64 {
+	return math.Sqrt(1 - (t-1)*(t-1))
+}
+
+func CircInOut(t float64) float64 {
+	if t < 0.5 {
+		return (1 - math.Sqrt(1-4*t*t)) / 2
+	}
+	return (math.Sqrt(1-(2-2*t)*(2-2*t)) + 1) / 2
+}
+
+// Overshoot of the Back easings.
+const backOvershoot = 1.70158
+
+func BackIn(t float64) float64 {
+	const c = backOvershoot
+	return (c+1)*t*t*t - c*t*t
+}
+
+func BackOut(t float64) float64 {
+	return 1 - BackIn(1-t)
+}
+
+func BackInOut(t float64) float64 {
+	const c = backOvershoot * 1.525
+	if t < 0.5 {
+		return 4 * t * t * ((c+1)*2*t - c) / 2
+	}
+	u := 2*t - 2
+	return (u*u*((c+1)*u+c) + 2) / 2
+}
+
+func ElasticIn(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return math.Max(0, math.Min(1, t))
+	}
+	return -math.Pow(2, 10*t-10) * math.Sin((10*t-10.75)*2*math.Pi/3)
+}
+
+func ElasticOut(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return math.Max(0, math.Min(1, t))
+	}
+	return math.Pow(2, -10*t)*math.Sin((10*t-0.75)*2*math.Pi/3) + 1
+}
+
+func ElasticInOut(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return math.Max(0, math.Min(1, t))
+	}
+
+	s := math.Sin((20*t - 11.125) * 2 * math.Pi / 4.5)
+	if t < 0.5 {
+		return -math.Pow(2, 20*t-10) * s / 2
+	}
+	return math.Pow(2, 10-20*t)*s/2 + 1
+}
+
+func BounceOut(t float64) float64 {
+	const n, d = 7.5625, 2.75
+
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+func BounceIn(t float64) float64 {
+	return 1 - BounceOut(1-t)
+}
+
+func BounceInOut(t float64) float64 {
+	if t < 0.5 {
+		return (1 - BounceOut(1-2*t)) / 2
+	}
+	return (1 + BounceOut(2*t-1)) / 2
+}
+
+// Returns easing following the cubic Bézier curve from (0, 0) to (1, 1)
+// with control points (x1, y1) and (x2, y2), as the CSS cubic-bezier()
+// timing function. x1 and x2 are clamped to [0, 1].
+func CubicBezier(x1, y1, x2, y2 float64) Easing {
+	x1 = math.Max(0, math.Min(1, x1))
+	x2 = math.Max(0, math.Min(1, x2))
+
+	// Polynomial coefficients of the curve coordinates.
+	cx := 3 * x1
+	bx := 3*(x2-x1) - cx
+	ax := 1 - cx - bx
+	cy := 3 * y1
+	by := 3*(y2-y1) - cy
+	ay := 1 - cy - by
+
+	curveX := func(s float64) float64 { return ((ax*s+bx)*s + cx) * s }
+	curveY := func(s float64) float64 { return ((ay*s+by)*s + cy) * s }
+	slopeX := func(s float64) float64 { return (3*ax*s+2*bx)*s + cx }
+
+	return func(t float64) float64 {
+		if t <= 0 || t >= 1 {
+			return math.Max(0, math.Min(1, t))
+		}
+
+		// Find the curve parameter at x = t with Newton's method, falling
+		// back to bisection where the slope is too flat.
+		s := t
+		for i := 0; i < 8; i++ {
+			x := curveX(s) - t
+			if math.Abs(x) < 1e-7 {
+				return curveY(s)
+			}
+			d := slopeX(s)
+			if math.Abs(d) < 1e-6 {
+				break
+			}
+			s -= x / d
+		}
+
+		lo, hi := 0.0, 1.0
+		s = t
+		for i := 0; i < 64; i++ {
+			x := curveX(s)
+			if math.Abs(x-t) < 1e-7 {
+				break
+			}
+			if x < t {
+				lo = s
+			} else {
+				hi = s
+			}
+			s = (lo + hi) / 2
+		}
+
+		return curveY(s)
+	}
+}
@@ -0,0 +1,82 @@
+package tween
+
+import (
+	"math"
+	"testing"
+)
+
+var easings = []struct {
+	name string
+	f    Easing
+}{
+	{"Linear", Linear},
+	{"QuadIn", QuadIn}, {"QuadOut", QuadOut}, {"QuadInOut", QuadInOut},
+	{"CubicIn", CubicIn}, {"CubicOut", CubicOut}, {"CubicInOut", CubicInOut},
+	{"QuartIn", QuartIn}, {"QuartOut", QuartOut}, {"QuartInOut", QuartInOut},
+	{"QuintIn", QuintIn}, {"QuintOut", QuintOut}, {"QuintInOut", QuintInOut},
+	{"SineIn", SineIn}, {"SineOut", SineOut}, {"SineInOut", SineInOut},
+	{"ExpoIn", ExpoIn}, {"ExpoOut", ExpoOut}, {"ExpoInOut", ExpoInOut},
+	{"CircIn", CircIn}, {"CircOut", CircOut}, {"CircInOut", CircInOut},
+	{"BackIn", BackIn}, {"BackOut", BackOut}, {"BackInOut", BackInOut},
+	{"ElasticIn", ElasticIn}, {"ElasticOut", ElasticOut}, {"ElasticInOut", ElasticInOut},
+	{"BounceIn", BounceIn}, {"BounceOut", BounceOut}, {"BounceInOut", BounceInOut},
+	{"CubicBezier(ease)", CubicBezier(0.25, 0.1, 0.25, 1)},
+	{"CubicBezier(ease-in-out)", CubicBezier(0.42, 0, 0.58, 1)},
+	{"CubicBezier(overshoot)", CubicBezier(0.3, -0.5, 0.7, 1.5)},
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEasingEndpoints(t *testing.T) {
+	for _, e := range easings {
+		if v := e.f(0); !near(v, 0) {
+			t.Errorf("%s(0) = %v, want 0", e.name, v)
+		}
+		if v := e.f(1); !near(v, 1) {
+			t.Errorf("%s(1) = %v, want 1", e.name, v)
+		}
+	}
+}
+
+func TestEasingSymmetry(t *testing.T) {
+	pairs := []struct {
+		name    string
+		in, out Easing
+	}{
+		{"Quad", QuadIn, QuadOut},
+		{"Cubic", CubicIn, CubicOut},
+		{"Sine", SineIn, SineOut},
+		{"Expo", ExpoIn, ExpoOut},
+		{"Circ", CircIn, CircOut},
+		{"Back", BackIn, BackOut},
+		{"Elastic", ElasticIn, ElasticOut},
+		{"Bounce", BounceIn, BounceOut},
+	}
+
+	// Out easings are In easings played backwards.
+	for _, p := range pairs {
+		for _, x := range []float64{0.1, 0.25, 0.5, 0.8} {
+			if in, out := p.in(x), 1-p.out(1-x); !near(in, out) {
+				t.Errorf("%sIn(%v) = %v, 1-%sOut(%v) = %v", p.name, x, in, p.name, 1-x, out)
+			}
+		}
+	}
+}
+
+func TestCubicBezier(t *testing.T) {
+	linear := CubicBezier(0, 0, 1, 1)
+	symmetric := CubicBezier(0.42, 0, 0.58, 1)
+	for _, x := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+		if v := linear(x); !near(v, x) {
+			t.Errorf("linear Bézier at %v = %v", x, v)
+		}
+		if a, b := symmetric(x), 1-symmetric(1-x); math.Abs(a-b) > 1e-6 {
+			t.Errorf("symmetric Bézier at %v = %v, mirrored %v", x, a, b)
+		}
+	}
+	if v := symmetric(0.5); math.Abs(v-0.5) > 1e-6 {
+		t.Errorf("symmetric Bézier at 0.5 = %v", v)
+	}
+}
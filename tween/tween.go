@@ -0,0 +1,265 @@
+// Package tween animates values over time with easing functions. Animations
+// are advanced explicitly by Update, so they can be driven by any loop and
+// tested without a clock.
+//
+// A Tween calls its Set function with the eased progress, which is mapped
+// to the animated value with Lerp or one of the adapters of the layergl
+// package. Tweens are composed with Sequence, Parallel, Delay and Repeat.
+package tween
+
+import (
+	"time"
+)
+
+// Repeat count of animations repeated until they are reset.
+const Forever = -1
+
+// Animation is a value changing over time.
+type Animation interface {
+	// Advances the animation by dt and returns the part of dt left after the
+	// animation finished, or 0 if it is still running.
+	Update(dt time.Duration) time.Duration
+
+	// Returns true when the animation has finished.
+	Done() bool
+
+	// Rewinds the animation to its start.
+	Reset()
+}
+
+// Tween animates a value from its start to its end over Duration.
+type Tween struct {
+	Duration time.Duration
+
+	// Easing of the progress, Linear if nil.
+	Ease Easing
+
+	// Called with the eased progress on every update. Progress 0 is the
+	// start value and 1 the end value.
+	Set func(t float64)
+
+	// Number of times the tween is played again after the first time, or
+	// Forever.
+	Repeat int
+
+	// Play every other repetition backwards.
+	Yoyo bool
+
+	// Called once when the tween finishes.
+	OnDone func()
+
+	elapsed   time.Duration
+	iteration int
+	done      bool
+}
+
+// Creates Tween calling set with the progress eased by ease.
+func New(duration time.Duration, ease Easing, set func(t float64)) *Tween {
+	return &Tween{Duration: duration, Ease: ease, Set: set}
+}
+
+// Creates Tween animating the float from the value from to the value to.
+func Float(p *float64, from, to float64, duration time.Duration, ease Easing) *Tween {
+	return New(duration, ease, func(t float64) {
+		*p = Lerp(from, to, t)
+	})
+}
+
+// Returns value between a and b at the progress t.
+func Lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func (tw *Tween) Update(dt time.Duration) time.Duration {
+	if tw.done {
+		return dt
+	}
+
+	tw.elapsed += dt
+	for tw.elapsed >= tw.Duration {
+		if tw.Duration <= 0 || tw.Repeat != Forever && tw.iteration >= tw.Repeat {
+			rest := tw.elapsed - tw.Duration
+			tw.elapsed = tw.Duration
+			tw.finish()
+			return rest
+		}
+
+		tw.elapsed -= tw.Duration
+		tw.iteration++
+	}
+
+	tw.set(float64(tw.elapsed) / float64(tw.Duration))
+	return 0
+}
+
+func (tw *Tween) finish() {
+	tw.done = true
+	tw.set(1)
+	if tw.OnDone != nil {
+		tw.OnDone()
+	}
+}
+
+// Calls Set with the eased progress of the current iteration.
+func (tw *Tween) set(t float64) {
+	if tw.Set == nil {
+		return
+	}
+	if tw.Yoyo && tw.iteration%2 == 1 {
+		t = 1 - t
+	}
+	if tw.Ease != nil {
+		t = tw.Ease(t)
+	}
+
+	tw.Set(t)
+}
+
+func (tw *Tween) Done() bool {
+	return tw.done
+}
+
+func (tw *Tween) Reset() {
+	tw.elapsed = 0
+	tw.iteration = 0
+	tw.done = false
+}
+
+// Returns the linear progress of the current iteration, from 0 to 1.
+func (tw *Tween) Progress() float64 {
+	if tw.Duration <= 0 {
+		if tw.done {
+			return 1
+		}
+		return 0
+	}
+	return float64(tw.elapsed) / float64(tw.Duration)
+}
+
+// Returns Tween doing nothing for the duration, for use in sequences.
+func Delay(duration time.Duration) *Tween {
+	return &Tween{Duration: duration}
+}
+
+type sequence struct {
+	animations []Animation
+	current    int
+}
+
+// Returns Animation playing the animations one after another.
+func Sequence(animations ...Animation) Animation {
+	return &sequence{animations: animations}
+}
+
+func (s *sequence) Update(dt time.Duration) time.Duration {
+	for s.current < len(s.animations) {
+		dt = s.animations[s.current].Update(dt)
+		if !s.animations[s.current].Done() {
+			return 0
+		}
+		s.current++
+	}
+
+	return dt
+}
+
+func (s *sequence) Done() bool {
+	return s.current >= len(s.animations)
+}
+
+func (s *sequence) Reset() {
+	for _, a := range s.animations {
+		a.Reset()
+	}
+	s.current = 0
+}
+
+type parallel struct {
+	animations []Animation
+}
+
+// Returns Animation playing the animations at the same time. It finishes
+// with the longest of them.
+func Parallel(animations ...Animation) Animation {
+	return &parallel{animations}
+}
+
+func (p *parallel) Update(dt time.Duration) time.Duration {
+	rest := dt
+	for _, a := range p.animations {
+		if a.Done() {
+			continue
+		}
+
+		if r := a.Update(dt); !a.Done() {
+			rest = 0
+		} else if r < rest {
+			rest = r
+		}
+	}
+
+	return rest
+}
+
+func (p *parallel) Done() bool {
+	for _, a := range p.animations {
+		if !a.Done() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *parallel) Reset() {
+	for _, a := range p.animations {
+		a.Reset()
+	}
+}
+
+type repeat struct {
+	animation Animation
+	count     int
+	iteration int
+	done      bool
+}
+
+// Returns Animation playing the animation count more times after the first
+// time, or until reset if count is Forever.
+func Repeat(animation Animation, count int) Animation {
+	return &repeat{animation: animation, count: count}
+}
+
+func (r *repeat) Update(dt time.Duration) time.Duration {
+	if r.done {
+		return dt
+	}
+
+	for {
+		rest := r.animation.Update(dt)
+		if !r.animation.Done() {
+			return 0
+		}
+
+		// An animation finishing without taking any time would be
+		// repeated endlessly.
+		if r.count != Forever && r.iteration >= r.count || rest == dt {
+			r.done = true
+			return rest
+		}
+
+		r.iteration++
+		r.animation.Reset()
+		dt = rest
+	}
+}
+
+func (r *repeat) Done() bool {
+	return r.done
+}
+
+func (r *repeat) Reset() {
+	r.animation.Reset()
+	r.iteration = 0
+	r.done = false
+}
@@ -0,0 +1,148 @@
+package tween
+
+import (
+	"testing"
+	"time"
+)
+
+const ms = time.Millisecond
+
+func TestTween(t *testing.T) {
+	var x float64
+	done := 0
+	tw := Float(&x, 10, 20, 100*ms, nil)
+	tw.OnDone = func() { done++ }
+
+	if rest := tw.Update(25 * ms); rest != 0 || !near(x, 12.5) || tw.Done() {
+		t.Fatalf("after 25ms: rest %v, x %v, done %v", rest, x, tw.Done())
+	}
+	if rest := tw.Update(100 * ms); rest != 25*ms || x != 20 || !tw.Done() {
+		t.Fatalf("after 125ms: rest %v, x %v, done %v", rest, x, tw.Done())
+	}
+	if rest := tw.Update(10 * ms); rest != 10*ms || done != 1 {
+		t.Fatalf("update of finished tween: rest %v, OnDone called %d times", rest, done)
+	}
+
+	tw.Reset()
+	tw.Update(50 * ms)
+	if !near(x, 15) || tw.Done() {
+		t.Fatalf("after reset: x %v, done %v", x, tw.Done())
+	}
+}
+
+func TestTweenYoyo(t *testing.T) {
+	tests := []struct {
+		repeat int
+		end    float64
+	}{
+		{0, 1},
+		{1, 0},
+		{2, 1},
+		{3, 0},
+	}
+
+	for _, test := range tests {
+		var x float64
+		tw := Float(&x, 0, 1, 10*ms, QuadIn)
+		tw.Repeat, tw.Yoyo = test.repeat, true
+
+		// Halfway through the second play, the value is on its way back.
+		if test.repeat >= 1 {
+			tw.Update(15 * ms)
+			if !near(x, 0.25) {
+				t.Errorf("repeat %d: x %v at 15ms, want 0.25", test.repeat, x)
+			}
+			tw.Reset()
+		}
+
+		rest := tw.Update(time.Duration(test.repeat+1)*10*ms + 3*ms)
+		if !tw.Done() || rest != 3*ms || x != test.end {
+			t.Errorf("repeat %d: done %v, rest %v, x %v, want true, 3ms, %v", test.repeat, tw.Done(), rest, x, test.end)
+		}
+	}
+}
+
+func TestSequenceCarriesLeftover(t *testing.T) {
+	var a, b float64
+	s := Sequence(
+		Float(&a, 0, 10, 10*ms, nil),
+		Delay(5*ms),
+		Float(&b, 0, 10, 10*ms, nil),
+	)
+
+	steps := []struct {
+		dt   time.Duration
+		rest time.Duration
+		a, b float64
+		done bool
+	}{
+		{5 * ms, 0, 5, 0, false},
+		// The first tween ends at 10ms, the delay at 15ms and the rest of
+		// the update moves the last tween.
+		{12 * ms, 0, 10, 2, false},
+		{20 * ms, 12 * ms, 10, 10, true},
+		{7 * ms, 7 * ms, 10, 10, true},
+	}
+	for i, step := range steps {
+		rest := s.Update(step.dt)
+		if rest != step.rest || !near(a, step.a) || !near(b, step.b) || s.Done() != step.done {
+			t.Fatalf("step %d: rest %v, a %v, b %v, done %v, want %v, %v, %v, %v",
+				i, rest, a, b, s.Done(), step.rest, step.a, step.b, step.done)
+		}
+	}
+
+	s.Reset()
+	s.Update(0)
+	if s.Done() || a != 0 {
+		t.Fatalf("after reset: done %v, a %v", s.Done(), a)
+	}
+}
+
+func TestParallelLeftover(t *testing.T) {
+	p := Parallel(Delay(10*ms), Delay(20*ms))
+
+	if rest := p.Update(15 * ms); rest != 0 || p.Done() {
+		t.Fatalf("after 15ms: rest %v, done %v", rest, p.Done())
+	}
+
+	// The leftover is measured from the end of the longest animation.
+	if rest := p.Update(10 * ms); rest != 5*ms || !p.Done() {
+		t.Fatalf("after 25ms: rest %v, done %v", rest, p.Done())
+	}
+
+	p.Reset()
+	if p.Done() {
+		t.Fatal("done after reset")
+	}
+	if rest := p.Update(time.Second); rest != time.Second-20*ms {
+		t.Fatalf("rest %v after a long update", rest)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	count := 0
+	tw := Delay(10 * ms)
+	tw.OnDone = func() { count++ }
+
+	r := Repeat(tw, 2)
+	if rest := r.Update(35 * ms); rest != 5*ms || count != 3 || !r.Done() {
+		t.Fatalf("rest %v, played %d times, done %v", rest, count, r.Done())
+	}
+}
+
+func TestRepeatForever(t *testing.T) {
+	count := 0
+	tw := Delay(10 * ms)
+	tw.OnDone = func() { count++ }
+
+	r := Repeat(tw, Forever)
+	if rest := r.Update(time.Second + 5*ms); rest != 0 || count != 100 || r.Done() {
+		t.Fatalf("rest %v, played %d times, done %v", rest, count, r.Done())
+	}
+
+	// An animation taking no time would repeat endlessly within one update.
+	zero := Repeat(Sequence(Delay(0), Delay(0)), Forever)
+	if rest := zero.Update(ms); rest != ms || !zero.Done() {
+		t.Fatalf("zero-duration animation: rest %v, done %v", rest, zero.Done())
+	}
+}
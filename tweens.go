@@ -0,0 +1,72 @@
+package layergl
+
+import (
+	"github.com/iostapyshyn/layergl/tween"
+	"math"
+	"time"
+)
+
+// Creates Tween animating the point from the value from to the value to.
+func TweenPoint(p *Point, from, to Point, duration time.Duration, ease tween.Easing) *tween.Tween {
+	return tween.New(duration, ease, func(t float64) {
+		*p = lerpPoint(from, to, t)
+	})
+}
+
+// Creates Tween animating the color from the value from to the value to.
+func TweenColor(c *Color, from, to Color, duration time.Duration, ease tween.Easing) *tween.Tween {
+	return tween.New(duration, ease, func(t float64) {
+		*c = lerpColor(from, to, t)
+	})
+}
+
+// Creates Tween animating the corners of the rectangle.
+func TweenRect(r *Rect, from, to Rect, duration time.Duration, ease tween.Easing) *tween.Tween {
+	return tween.New(duration, ease, func(t float64) {
+		*r = Rect{
+			tween.Lerp(from.X1, to.X1, t),
+			tween.Lerp(from.Y1, to.Y1, t),
+			tween.Lerp(from.X2, to.X2, t),
+			tween.Lerp(from.Y2, to.Y2, t),
+		}
+	})
+}
+
+// Creates Tween animating the transformation. Translation, rotation and
+// scale are interpolated separately, the rotation along the shorter way, so
+// that intermediate transformations are not distorted. Skew is not
+// preserved.
+func TweenTransform(tr *Transform, from, to Transform, duration time.Duration, ease tween.Easing) *tween.Tween {
+	a, b := decompose(from), decompose(to)
+
+	// Turn by at most half a circle.
+	turn := math.Remainder(b.angle-a.angle, 2*math.Pi)
+
+	return tween.New(duration, ease, func(t float64) {
+		*tr = Translation(tween.Lerp(a.x, b.x, t), tween.Lerp(a.y, b.y, t)).
+			Mul(Rotation(a.angle + turn*t)).
+			Mul(Scaling(tween.Lerp(a.sx, b.sx, t), tween.Lerp(a.sy, b.sy, t)))
+	})
+}
+
+func lerpPoint(a, b Point, t float64) Point {
+	return Point{tween.Lerp(a.X, b.X, t), tween.Lerp(a.Y, b.Y, t)}
+}
+
+// Components of a transformation applying scale, rotation and translation,
+// in this order.
+type transformParts struct {
+	x, y   float64
+	angle  float64
+	sx, sy float64
+}
+
+func decompose(t Transform) transformParts {
+	sx := math.Hypot(t.A, t.B)
+	p := transformParts{x: t.E, y: t.F, angle: math.Atan2(t.B, t.A), sx: sx}
+	if sx != 0 {
+		p.sy = (t.A*t.D - t.B*t.C) / sx
+	}
+
+	return p
+}
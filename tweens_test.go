@@ -0,0 +1,42 @@
+package layergl
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTweenTransformShortWay(t *testing.T) {
+	tests := []struct {
+		from, to float64
+		halfway  float64
+	}{
+		{0, math.Pi / 2, math.Pi / 4},
+		// From 170° to -170° through 180°, not through 0°.
+		{170 * math.Pi / 180, -170 * math.Pi / 180, math.Pi},
+		{-170 * math.Pi / 180, 170 * math.Pi / 180, math.Pi},
+		{10 * math.Pi / 180, 350 * math.Pi / 180, 0},
+	}
+
+	for _, test := range tests {
+		from := Translation(10, 20).Mul(Rotation(test.from)).Mul(Scaling(2, 2))
+		to := Translation(30, 40).Mul(Rotation(test.to)).Mul(Scaling(4, 4))
+
+		var tr Transform
+		tw := TweenTransform(&tr, from, to, time.Second, nil)
+		tw.Update(time.Second / 2)
+
+		p := decompose(tr)
+		if turn := math.Remainder(p.angle-test.halfway, 2*math.Pi); math.Abs(turn) > 1e-9 {
+			t.Errorf("%v to %v: angle %v halfway, want %v", test.from, test.to, p.angle, test.halfway)
+		}
+		if math.Abs(p.x-20) > 1e-9 || math.Abs(p.y-30) > 1e-9 || math.Abs(p.sx-3) > 1e-9 || math.Abs(p.sy-3) > 1e-9 {
+			t.Errorf("%v to %v: halfway parts %+v", test.from, test.to, p)
+		}
+
+		tw.Update(time.Second)
+		if d := decompose(tr); math.Abs(math.Remainder(d.angle-test.to, 2*math.Pi)) > 1e-9 {
+			t.Errorf("%v to %v: ends at angle %v", test.from, test.to, d.angle)
+		}
+	}
+}
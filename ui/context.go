@@ -0,0 +1,296 @@
+// Package ui is an immediate-mode GUI drawn with layergl. Widgets are
+// functions called every frame between Begin and End; they draw themselves
+// and report interaction through their return values.
+package ui
+
+import (
+	"strings"
+)
+
+type Key int
+
+const (
+	KeyTab Key = iota
+	KeyEnter
+	KeySpace
+	KeyEscape
+	KeyBackspace
+	KeyDelete
+	KeyLeft
+	KeyRight
+	KeyUp
+	KeyDown
+	KeyHome
+	KeyEnd
+)
+
+// Key press or, if text is set, a typed character.
+type keyEvent struct {
+	key   Key
+	shift bool
+	text  bool
+	r     rune
+}
+
+// Input of a frame, accumulated from the events.
+type input struct {
+	mouseX, mouseY    float64
+	down              bool
+	pressed, released bool
+	scroll            float64
+	keys              []keyEvent
+}
+
+// Context holds the state of the GUI between frames.
+type Context struct {
+	Theme Theme
+
+	r Renderer
+
+	events input
+	in     input
+
+	// Widget under the mouse, widget being pressed or dragged and widget
+	// receiving keyboard input.
+	hot, active, focus string
+
+	// Focusable widgets of the current and the previous frame in the order
+	// of Tab.
+	focusables, lastFocusables []string
+
+	containers []*container
+	clips      []Rect
+
+	windows     map[string]*window
+	windowRects []namedRect
+	hoverWindow string
+	dragX       float64
+	dragY       float64
+
+	scroll  map[string]float64
+	cursors map[string]int
+}
+
+type container struct {
+	id     string
+	rect   Rect
+	y      float64
+	window string
+}
+
+type window struct {
+	rect Rect
+}
+
+type namedRect struct {
+	name string
+	rect Rect
+}
+
+func New(r Renderer) *Context {
+	return &Context{
+		Theme:   DefaultTheme(),
+		r:       r,
+		windows: make(map[string]*window),
+		scroll:  make(map[string]float64),
+		cursors: make(map[string]int),
+	}
+}
+
+// Moves the mouse to x, y in GUI coordinates.
+func (c *Context) MouseMove(x, y float64) {
+	c.events.mouseX, c.events.mouseY = x, y
+}
+
+// Presses or releases the primary mouse button.
+func (c *Context) MouseButton(down bool) {
+	if down && !c.events.down {
+		c.events.pressed = true
+	}
+	if !down && c.events.down {
+		c.events.released = true
+	}
+	c.events.down = down
+}
+
+// Scrolls by the number of rows, positive up.
+func (c *Context) Scroll(rows float64) {
+	c.events.scroll += rows
+}
+
+func (c *Context) PressKey(k Key, shift bool) {
+	c.events.keys = append(c.events.keys, keyEvent{key: k, shift: shift})
+}
+
+// Types the text into the focused text input.
+func (c *Context) TypeText(text string) {
+	for _, r := range text {
+		c.events.keys = append(c.events.keys, keyEvent{text: true, r: r})
+	}
+}
+
+// Begins a frame with the events received since the last frame. Widgets
+// outside of windows are laid out in the bounds.
+func (c *Context) Begin(bounds Rect) {
+	c.in = c.events
+	c.events.pressed, c.events.released = false, false
+	c.events.scroll = 0
+	c.events.keys = nil
+
+	c.hot = ""
+	c.lastFocusables, c.focusables = c.focusables, c.lastFocusables[:0]
+
+	// Windows are drawn in the call order, so the last one is on top.
+	c.hoverWindow = ""
+	for _, w := range c.windowRects {
+		if w.rect.Contains(c.in.mouseX, c.in.mouseY) {
+			c.hoverWindow = w.name
+		}
+	}
+	c.windowRects = c.windowRects[:0]
+
+	for _, k := range c.in.keys {
+		if !k.text && k.key == KeyTab {
+			c.moveFocus(k.shift)
+		}
+	}
+
+	pad := c.Theme.Padding
+	c.containers = []*container{{
+		rect: Rect{bounds.X + pad, bounds.Y + pad, bounds.W - 2*pad, bounds.H - 2*pad},
+		y:    bounds.Y + pad,
+	}}
+	c.clips = []Rect{bounds}
+}
+
+// Ends the frame.
+func (c *Context) End() {
+	if c.in.released || !c.in.down {
+		c.active = ""
+	}
+
+	// Clicking outside of any widget or removing the focused widget clears
+	// the focus.
+	if c.in.pressed && c.hot == "" {
+		c.focus = ""
+	}
+	if c.focus != "" && !contains(c.focusables, c.focus) {
+		c.focus = ""
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Moves the focus to the next or the previous focusable widget.
+func (c *Context) moveFocus(back bool) {
+	n := len(c.lastFocusables)
+	if n == 0 {
+		return
+	}
+
+	i := -1
+	for j, id := range c.lastFocusables {
+		if id == c.focus {
+			i = j
+		}
+	}
+
+	switch {
+	case i < 0 && back:
+		i = n - 1
+	case i < 0:
+		i = 0
+	case back:
+		i = (i + n - 1) % n
+	default:
+		i = (i + 1) % n
+	}
+	c.focus = c.lastFocusables[i]
+}
+
+// Returns the ID of the widget with the label in the current container and
+// the displayed text. Text after "##" is only a part of the ID, to tell
+// apart widgets with the same text.
+func (c *Context) id(label string) (id, text string) {
+	text = label
+	if i := strings.Index(label, "##"); i >= 0 {
+		text = label[:i]
+	}
+	return c.top().id + "/" + label, text
+}
+
+func (c *Context) top() *container {
+	return c.containers[len(c.containers)-1]
+}
+
+// Allocates the next row of the current container.
+func (c *Context) next(height float64) Rect {
+	t := c.top()
+	r := Rect{t.rect.X, t.y, t.rect.W, height}
+	t.y += height + c.Theme.Spacing
+	return r
+}
+
+func (c *Context) pushClip(r Rect) {
+	r = r.intersect(c.clips[len(c.clips)-1])
+	c.clips = append(c.clips, r)
+	c.r.PushClip(r)
+}
+
+func (c *Context) popClip() {
+	c.clips = c.clips[:len(c.clips)-1]
+	c.r.PopClip()
+}
+
+// Returns true if the mouse is over the visible part of the rectangle and not
+// covered by another window.
+func (c *Context) hovered(r Rect) bool {
+	x, y := c.in.mouseX, c.in.mouseY
+	return r.Contains(x, y) && c.clips[len(c.clips)-1].Contains(x, y) &&
+		c.top().window == c.hoverWindow
+}
+
+// Handles the mouse for the widget and returns true if it was clicked.
+func (c *Context) interact(id string, r Rect, focusable bool) (clicked bool) {
+	if focusable {
+		c.focusables = append(c.focusables, id)
+	}
+
+	if c.hovered(r) {
+		c.hot = id
+		if c.in.pressed {
+			c.active = id
+			if focusable {
+				c.focus = id
+			}
+		}
+	}
+
+	return c.in.released && c.active == id && c.hot == id
+}
+
+// Returns true if the key was pressed this frame while the widget has focus.
+func (c *Context) keyPressed(id string, k Key) bool {
+	if c.focus != id {
+		return false
+	}
+	for _, e := range c.in.keys {
+		if !e.text && e.key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the widget has the keyboard focus.
+func (c *Context) Focused(label string) bool {
+	id, _ := c.id(label)
+	return c.focus == id
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"github.com/iostapyshyn/layergl"
+	"math"
+)
+
+// Rect is a rectangle in GUI coordinates: pixels from the top left corner
+// of the screen, Y pointing down.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+func (r Rect) intersect(s Rect) Rect {
+	x1, y1 := math.Max(r.X, s.X), math.Max(r.Y, s.Y)
+	x2, y2 := math.Min(r.X+r.W, s.X+s.W), math.Min(r.Y+r.H, s.Y+s.H)
+	if x2 < x1 {
+		x2 = x1
+	}
+	if y2 < y1 {
+		y2 = y1
+	}
+	return Rect{x1, y1, x2 - x1, y2 - y1}
+}
+
+// Renderer draws the widgets. Widget logic only depends on this interface,
+// so it can be run with a fake renderer and no GPU.
+type Renderer interface {
+	DrawRect(r Rect, color layergl.Color)
+
+	// Draws a line of text with the top left corner of its box at x, y.
+	DrawText(x, y float64, color layergl.Color, text string)
+
+	// Returns the width and the line height of the text.
+	MeasureText(text string) (width, height float64)
+
+	// Restricts drawing to the rectangle until the matching PopClip. Clips
+	// are nested.
+	PushClip(r Rect)
+	PopClip()
+}
+
+// GLRenderer draws with layergl, flipping the Y axis of the screen.
+type GLRenderer struct {
+	Font  *layergl.Font
+	Scale float64
+
+	// Height of the screen in pixels.
+	Height float64
+}
+
+func (g *GLRenderer) rect(r Rect) layergl.Rect {
+	return layergl.Rect{X1: r.X, Y1: g.Height - r.Y - r.H, X2: r.X + r.W, Y2: g.Height - r.Y}
+}
+
+func (g *GLRenderer) DrawRect(r Rect, color layergl.Color) {
+	layergl.DrawRect(g.rect(r), color)
+}
+
+func (g *GLRenderer) DrawText(x, y float64, color layergl.Color, text string) {
+	baseline := layergl.Point{X: x, Y: g.Height - y - g.Font.Ascent(g.Scale)}
+	g.Font.Printf(baseline, color, g.Scale, "%s", text)
+}
+
+func (g *GLRenderer) MeasureText(text string) (width, height float64) {
+	return g.Font.Measure(text, g.Scale)
+}
+
+func (g *GLRenderer) PushClip(r Rect) {
+	layergl.PushClip(g.rect(r))
+}
+
+func (g *GLRenderer) PopClip() {
+	layergl.PopClip()
+}
@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"github.com/iostapyshyn/layergl"
+)
+
+// Theme holds the colors and metrics of the widgets.
+type Theme struct {
+	Text         layergl.Color
+	Window       layergl.Color
+	TitleBar     layergl.Color
+	Widget       layergl.Color
+	WidgetHot    layergl.Color
+	WidgetActive layergl.Color
+	Accent       layergl.Color
+	Focus        layergl.Color
+
+	// Space inside windows and widgets, between widgets and the height of
+	// a row of widgets.
+	Padding, Spacing, RowHeight float64
+}
+
+func DefaultTheme() Theme {
+	return Theme{
+		Text:         layergl.Color{R: 0.9, G: 0.9, B: 0.9, A: 1},
+		Window:       layergl.Color{R: 0.12, G: 0.12, B: 0.14, A: 0.95},
+		TitleBar:     layergl.Color{R: 0.2, G: 0.25, B: 0.35, A: 1},
+		Widget:       layergl.Color{R: 0.25, G: 0.25, B: 0.28, A: 1},
+		WidgetHot:    layergl.Color{R: 0.32, G: 0.32, B: 0.36, A: 1},
+		WidgetActive: layergl.Color{R: 0.4, G: 0.4, B: 0.45, A: 1},
+		Accent:       layergl.Color{R: 0.3, G: 0.55, B: 0.9, A: 1},
+		Focus:        layergl.Color{R: 0.9, G: 0.7, B: 0.2, A: 1},
+		Padding:      6,
+		Spacing:      4,
+		RowHeight:    24,
+	}
+}
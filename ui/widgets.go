@@ -0,0 +1,305 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/iostapyshyn/layergl"
+	"math"
+)
+
+// Draws the text vertically centered in the rectangle, after the padding.
+func (c *Context) text(r Rect, text string, color layergl.Color) {
+	_, h := c.r.MeasureText(text)
+	c.r.DrawText(r.X+c.Theme.Padding, r.Y+(r.H-h)/2, color, text)
+}
+
+// Draws the text centered in the rectangle.
+func (c *Context) centeredText(r Rect, text string, color layergl.Color) {
+	w, h := c.r.MeasureText(text)
+	c.r.DrawText(r.X+(r.W-w)/2, r.Y+(r.H-h)/2, color, text)
+}
+
+func (c *Context) outline(r Rect, color layergl.Color) {
+	c.r.DrawRect(Rect{r.X, r.Y, r.W, 1}, color)
+	c.r.DrawRect(Rect{r.X, r.Y + r.H - 1, r.W, 1}, color)
+	c.r.DrawRect(Rect{r.X, r.Y, 1, r.H}, color)
+	c.r.DrawRect(Rect{r.X + r.W - 1, r.Y, 1, r.H}, color)
+}
+
+// Returns the background color of the widget in its current state.
+func (c *Context) widgetColor(id string) layergl.Color {
+	switch {
+	case c.active == id:
+		return c.Theme.WidgetActive
+	case c.hot == id:
+		return c.Theme.WidgetHot
+	}
+	return c.Theme.Widget
+}
+
+func (c *Context) drawFocus(id string, r Rect) {
+	if c.focus == id {
+		c.outline(r, c.Theme.Focus)
+	}
+}
+
+func (c *Context) Label(text string) {
+	r := c.next(c.Theme.RowHeight)
+	c.text(r, text, c.Theme.Text)
+}
+
+// Returns true if the button was clicked or activated with Enter or Space
+// while focused.
+func (c *Context) Button(label string) bool {
+	id, text := c.id(label)
+	r := c.next(c.Theme.RowHeight)
+
+	clicked := c.interact(id, r, true)
+	clicked = clicked || c.keyPressed(id, KeyEnter) || c.keyPressed(id, KeySpace)
+
+	c.r.DrawRect(r, c.widgetColor(id))
+	c.centeredText(r, text, c.Theme.Text)
+	c.drawFocus(id, r)
+
+	return clicked
+}
+
+// Toggles the value when clicked or activated with Space. Returns true if
+// the value changed.
+func (c *Context) Checkbox(label string, value *bool) bool {
+	id, text := c.id(label)
+	r := c.next(c.Theme.RowHeight)
+
+	changed := c.interact(id, r, true) || c.keyPressed(id, KeySpace)
+	if changed {
+		*value = !*value
+	}
+
+	box := Rect{r.X, r.Y, r.H, r.H}
+	c.r.DrawRect(box, c.widgetColor(id))
+	if *value {
+		inset := r.H / 4
+		c.r.DrawRect(Rect{box.X + inset, box.Y + inset, box.W - 2*inset, box.H - 2*inset}, c.Theme.Accent)
+	}
+	c.text(Rect{r.X + r.H, r.Y, r.W - r.H, r.H}, text, c.Theme.Text)
+	c.drawFocus(id, r)
+
+	return changed
+}
+
+// Edits the value between min and max by dragging, or with the Left and
+// Right keys in steps of 1/100 of the range. Returns true if the value
+// changed.
+func (c *Context) Slider(label string, value *float64, min, max float64) bool {
+	id, text := c.id(label)
+	r := c.next(c.Theme.RowHeight)
+	old := *value
+
+	c.interact(id, r, true)
+	if c.active == id && c.in.down && r.W > 0 {
+		*value = min + (c.in.mouseX-r.X)/r.W*(max-min)
+	}
+
+	step := (max - min) / 100
+	if c.keyPressed(id, KeyLeft) {
+		*value -= step
+	}
+	if c.keyPressed(id, KeyRight) {
+		*value += step
+	}
+	*value = math.Max(min, math.Min(max, *value))
+
+	c.r.DrawRect(r, c.widgetColor(id))
+	if max > min {
+		c.r.DrawRect(Rect{r.X, r.Y, r.W * (*value - min) / (max - min), r.H}, c.Theme.Accent)
+	}
+	c.centeredText(r, fmt.Sprintf("%s: %.2f", text, *value), c.Theme.Text)
+	c.drawFocus(id, r)
+
+	return *value != old
+}
+
+// Edits the text while focused. Returns true if the text changed.
+func (c *Context) TextInput(label string, text *string) bool {
+	id, _ := c.id(label)
+	r := c.next(c.Theme.RowHeight)
+	c.interact(id, r, true)
+
+	runes := []rune(*text)
+	cursor, ok := c.cursors[id]
+	if !ok || cursor > len(runes) {
+		cursor = len(runes)
+	}
+
+	changed := false
+	if c.focus == id {
+		for _, e := range c.in.keys {
+			if e.text {
+				runes = append(runes[:cursor], append([]rune{e.r}, runes[cursor:]...)...)
+				cursor++
+				changed = true
+				continue
+			}
+
+			switch e.key {
+			case KeyBackspace:
+				if cursor > 0 {
+					runes = append(runes[:cursor-1], runes[cursor:]...)
+					cursor--
+					changed = true
+				}
+			case KeyDelete:
+				if cursor < len(runes) {
+					runes = append(runes[:cursor], runes[cursor+1:]...)
+					changed = true
+				}
+			case KeyLeft:
+				if cursor > 0 {
+					cursor--
+				}
+			case KeyRight:
+				if cursor < len(runes) {
+					cursor++
+				}
+			case KeyHome:
+				cursor = 0
+			case KeyEnd:
+				cursor = len(runes)
+			}
+		}
+	}
+	c.cursors[id] = cursor
+	if changed {
+		*text = string(runes)
+	}
+
+	c.r.DrawRect(r, c.widgetColor(id))
+	c.pushClip(r)
+	c.text(r, *text, c.Theme.Text)
+	if c.focus == id {
+		w, h := c.r.MeasureText(string(runes[:cursor]))
+		c.r.DrawRect(Rect{r.X + c.Theme.Padding + w, r.Y + (r.H-h)/2, 1, h}, c.Theme.Text)
+	}
+	c.popClip()
+	c.drawFocus(id, r)
+
+	return changed
+}
+
+// Shows the items and selects the clicked one, or moves the selection with
+// the Up and Down keys while focused. Returns true if the selection changed.
+func (c *Context) List(label string, items []string, selected *int) bool {
+	id, _ := c.id(label)
+	old := *selected
+
+	for i, item := range items {
+		r := c.next(c.Theme.RowHeight)
+		if c.interact(fmt.Sprintf("%s/%d", id, i), r, false) {
+			*selected = i
+			c.focus = id
+		}
+
+		color := c.Theme.Widget
+		if i == *selected {
+			color = c.Theme.Accent
+		} else if c.hot == fmt.Sprintf("%s/%d", id, i) {
+			color = c.Theme.WidgetHot
+		}
+		c.r.DrawRect(r, color)
+		c.text(r, item, c.Theme.Text)
+	}
+
+	// The list takes focus through its items, but is one stop of Tab.
+	c.focusables = append(c.focusables, id)
+	if c.keyPressed(id, KeyUp) && *selected > 0 {
+		*selected--
+	}
+	if c.keyPressed(id, KeyDown) && *selected < len(items)-1 {
+		*selected++
+	}
+
+	return *selected != old
+}
+
+// Begins a panel of the given height scrolled with the mouse wheel. Widgets
+// until EndScroll are clipped to the panel.
+func (c *Context) BeginScroll(label string, height float64) {
+	id, _ := c.id(label)
+	r := c.next(height)
+
+	c.pushClip(r)
+	c.containers = append(c.containers, &container{
+		id:     id,
+		rect:   Rect{r.X, r.Y, r.W - c.Theme.Padding, r.H},
+		y:      r.Y - c.scroll[id],
+		window: c.top().window,
+	})
+}
+
+func (c *Context) EndScroll() {
+	t := c.top()
+	c.containers = c.containers[:len(c.containers)-1]
+	c.popClip()
+
+	r := Rect{t.rect.X, t.rect.Y, t.rect.W + c.Theme.Padding, t.rect.H}
+	content := t.y - (r.Y - c.scroll[t.id])
+	limit := math.Max(0, content-r.H)
+
+	scroll := c.scroll[t.id]
+	if c.in.scroll != 0 && c.hovered(r) {
+		scroll -= c.in.scroll * c.Theme.RowHeight
+		c.in.scroll = 0
+	}
+	c.scroll[t.id] = math.Max(0, math.Min(limit, scroll))
+
+	if limit > 0 {
+		bar := Rect{r.X + r.W - c.Theme.Padding, r.Y, c.Theme.Padding, r.H}
+		c.r.DrawRect(bar, c.Theme.Widget)
+		thumb := r.H * r.H / content
+		y := bar.Y + (r.H-thumb)*c.scroll[t.id]/limit
+		c.r.DrawRect(Rect{bar.X, y, bar.W, thumb}, c.Theme.WidgetHot)
+	}
+}
+
+// Begins a window that can be moved by its title bar. The rectangle is used
+// when the window is shown for the first time. Windows overlap in the order
+// of the calls.
+func (c *Context) BeginWindow(title string, rect Rect) {
+	id, text := c.id(title)
+	w, ok := c.windows[id]
+	if !ok {
+		w = &window{rect: rect}
+		c.windows[id] = w
+	}
+	c.windowRects = append(c.windowRects, namedRect{id, w.rect})
+
+	// The window is its own container for the hit testing of the title bar.
+	c.containers = append(c.containers, &container{id: id, window: id})
+
+	bar := Rect{w.rect.X, w.rect.Y, w.rect.W, c.Theme.RowHeight}
+	barID := id + "#title"
+	c.interact(barID, bar, false)
+	if c.active == barID {
+		if c.in.pressed {
+			c.dragX, c.dragY = c.in.mouseX-w.rect.X, c.in.mouseY-w.rect.Y
+		}
+		w.rect.X, w.rect.Y = c.in.mouseX-c.dragX, c.in.mouseY-c.dragY
+		bar.X, bar.Y = w.rect.X, w.rect.Y
+	}
+
+	c.r.DrawRect(w.rect, c.Theme.Window)
+	c.r.DrawRect(bar, c.Theme.TitleBar)
+	c.text(bar, text, c.Theme.Text)
+
+	pad := c.Theme.Padding
+	body := Rect{w.rect.X, bar.Y + bar.H, w.rect.W, w.rect.H - bar.H}
+	c.pushClip(body)
+
+	t := c.top()
+	t.rect = Rect{body.X + pad, body.Y + pad, body.W - 2*pad, body.H - 2*pad}
+	t.y = t.rect.Y
+}
+
+func (c *Context) EndWindow() {
+	c.containers = c.containers[:len(c.containers)-1]
+	c.popClip()
+}
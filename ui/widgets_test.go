@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"github.com/iostapyshyn/layergl"
+	"testing"
+)
+
+type drawnRect struct {
+	rect  Rect
+	color layergl.Color
+}
+
+// fakeRenderer records the drawing and measures text in a fixed-width font,
+// so that widgets can be tested without a GPU.
+type fakeRenderer struct {
+	rects []drawnRect
+	texts []string
+	clips int
+}
+
+func (f *fakeRenderer) DrawRect(r Rect, color layergl.Color) {
+	f.rects = append(f.rects, drawnRect{r, color})
+}
+
+func (f *fakeRenderer) DrawText(x, y float64, color layergl.Color, text string) {
+	f.texts = append(f.texts, text)
+}
+
+func (f *fakeRenderer) MeasureText(text string) (width, height float64) {
+	return 7 * float64(len([]rune(text))), 14
+}
+
+func (f *fakeRenderer) PushClip(r Rect) {
+	f.clips++
+}
+
+func (f *fakeRenderer) PopClip() {
+	f.clips--
+}
+
+var screen = Rect{0, 0, 400, 300}
+
+// With the default theme, widgets outside of windows start at x = 6 and are
+// 388 pixels wide. Rows are 24 pixels high and 28 pixels apart.
+const (
+	rowX     = 6
+	rowWidth = 388
+)
+
+func rowY(row int) float64 {
+	return 6 + 28*float64(row) + 12
+}
+
+func newTestContext() (*Context, *fakeRenderer) {
+	r := new(fakeRenderer)
+	return New(r), r
+}
+
+// Runs a frame with the widgets and checks that clips are balanced.
+func frame(t *testing.T, c *Context, widgets func()) {
+	t.Helper()
+
+	f := c.r.(*fakeRenderer)
+	f.rects, f.texts = nil, nil
+
+	c.Begin(screen)
+	widgets()
+	c.End()
+
+	if f.clips != 0 {
+		t.Fatalf("unbalanced clips: %d", f.clips)
+	}
+}
+
+func TestButtonClick(t *testing.T) {
+	c, _ := newTestContext()
+	var clicked bool
+	button := func() { clicked = c.Button("OK") }
+
+	c.MouseMove(100, rowY(0))
+	c.MouseButton(true)
+	frame(t, c, button)
+	if clicked {
+		t.Fatal("clicked on press")
+	}
+
+	c.MouseButton(false)
+	frame(t, c, button)
+	if !clicked {
+		t.Fatal("not clicked on release")
+	}
+
+	// Releasing outside of the button cancels the click.
+	c.MouseButton(true)
+	frame(t, c, button)
+	c.MouseMove(100, rowY(3))
+	c.MouseButton(false)
+	frame(t, c, button)
+	if clicked {
+		t.Fatal("clicked after release outside")
+	}
+
+	// The button was focused by the press and is activated with Enter.
+	c.PressKey(KeyEnter, false)
+	frame(t, c, button)
+	if !clicked {
+		t.Fatal("not activated with Enter")
+	}
+}
+
+func TestSliderDrag(t *testing.T) {
+	c, _ := newTestContext()
+	value := 0.0
+	slider := func() { c.Slider("Value", &value, 0, 10) }
+
+	steps := []struct {
+		x    float64
+		down bool
+		want float64
+	}{
+		{rowX + rowWidth/4, true, 2.5},
+		{rowX + rowWidth*3/4, true, 7.5},
+		{rowX + rowWidth*2, true, 10},
+		{rowX - 100, true, 0},
+		{rowX + rowWidth/2, true, 5},
+		{rowX + rowWidth/2, false, 5},
+		{rowX + rowWidth/4, false, 5},
+	}
+	for i, s := range steps {
+		c.MouseMove(s.x, rowY(0))
+		c.MouseButton(s.down)
+		frame(t, c, slider)
+		if value != s.want {
+			t.Fatalf("step %d: value %v, want %v", i, value, s.want)
+		}
+	}
+
+	c.PressKey(KeyRight, false)
+	frame(t, c, slider)
+	if value != 5.1 {
+		t.Fatalf("value %v after Right, want 5.1", value)
+	}
+}
+
+func TestTextInputEditing(t *testing.T) {
+	c, _ := newTestContext()
+	first, second := "", "x"
+	inputs := func() {
+		c.TextInput("first", &first)
+		c.TextInput("second", &second)
+	}
+
+	frame(t, c, inputs)
+	c.TypeText("ignored")
+	frame(t, c, inputs)
+	if first != "" {
+		t.Fatalf("unfocused input changed to %q", first)
+	}
+
+	c.MouseMove(100, rowY(0))
+	c.MouseButton(true)
+	frame(t, c, inputs)
+	c.MouseButton(false)
+	frame(t, c, inputs)
+	if !c.Focused("first") {
+		t.Fatal("input not focused by click")
+	}
+
+	// Keys and text are applied in the order they were received.
+	c.TypeText("hello")
+	c.PressKey(KeyLeft, false)
+	c.PressKey(KeyLeft, false)
+	c.PressKey(KeyBackspace, false)
+	c.TypeText("X")
+	c.PressKey(KeyHome, false)
+	c.PressKey(KeyDelete, false)
+	frame(t, c, inputs)
+	if first != "eXlo" {
+		t.Fatalf("text %q, want %q", first, "eXlo")
+	}
+
+	c.PressKey(KeyTab, false)
+	c.TypeText("y")
+	frame(t, c, inputs)
+	if !c.Focused("second") || second != "xy" || first != "eXlo" {
+		t.Fatalf("after Tab: focused second %v, texts %q, %q", c.Focused("second"), first, second)
+	}
+
+	// Clicking outside of any widget clears the focus.
+	c.MouseMove(100, rowY(5))
+	c.MouseButton(true)
+	frame(t, c, inputs)
+	c.MouseButton(false)
+	c.TypeText("z")
+	frame(t, c, inputs)
+	if c.Focused("second") || second != "xy" {
+		t.Fatalf("after click outside: focused %v, text %q", c.Focused("second"), second)
+	}
+}
+
+func TestWindowDrag(t *testing.T) {
+	c, r := newTestContext()
+	win := func() {
+		c.BeginWindow("Window", Rect{50, 50, 200, 150})
+		c.Label("content")
+		c.EndWindow()
+	}
+	drawn := func() Rect {
+		for _, d := range r.rects {
+			if d.color == c.Theme.Window {
+				return d.rect
+			}
+		}
+		t.Fatal("window not drawn")
+		return Rect{}
+	}
+
+	frame(t, c, win)
+
+	// Grab the title bar 10 pixels from the corner and drag.
+	c.MouseMove(60, 60)
+	c.MouseButton(true)
+	frame(t, c, win)
+	c.MouseMove(160, 110)
+	frame(t, c, win)
+	if got, want := drawn(), (Rect{150, 100, 200, 150}); got != want {
+		t.Fatalf("window at %v while dragging, want %v", got, want)
+	}
+
+	c.MouseButton(false)
+	frame(t, c, win)
+	c.MouseMove(300, 250)
+	frame(t, c, win)
+	if got, want := drawn(), (Rect{150, 100, 200, 150}); got != want {
+		t.Fatalf("window at %v after release, want %v", got, want)
+	}
+
+	// Dragging the body does not move the window.
+	c.MouseMove(200, 200)
+	c.MouseButton(true)
+	frame(t, c, win)
+	c.MouseMove(250, 250)
+	frame(t, c, win)
+	c.MouseButton(false)
+	frame(t, c, win)
+	if got, want := drawn(), (Rect{150, 100, 200, 150}); got != want {
+		t.Fatalf("window at %v after dragging the body, want %v", got, want)
+	}
+}
+
+func TestOverlappingWindowsHover(t *testing.T) {
+	c, _ := newTestContext()
+	var clickedA, clickedB bool
+	windows := func() {
+		c.BeginWindow("A", Rect{0, 0, 200, 200})
+		clickedA = c.Button("button")
+		c.EndWindow()
+
+		// Drawn last, so B is on top of A.
+		c.BeginWindow("B", Rect{50, 10, 200, 200})
+		clickedB = c.Button("button")
+		c.EndWindow()
+	}
+	click := func(x, y float64) {
+		c.MouseMove(x, y)
+		c.MouseButton(true)
+		frame(t, c, windows)
+		c.MouseButton(false)
+		frame(t, c, windows)
+	}
+
+	frame(t, c, windows)
+
+	// The buttons overlap at 100, 45; only the one of the top window is
+	// clicked.
+	click(100, 45)
+	if clickedA || !clickedB {
+		t.Fatalf("click in overlap: A %v, B %v, want only B", clickedA, clickedB)
+	}
+
+	// Outside of B, the button of A is reachable.
+	click(20, 45)
+	if !clickedA || clickedB {
+		t.Fatalf("click outside of B: A %v, B %v, want only A", clickedA, clickedB)
+	}
+}